@@ -44,6 +44,41 @@ func core() error {
 	if err := setLogLevel(); err != nil {
 		return fmt.Errorf("set a log level: %w", err)
 	}
+	args := os.Args[1:]
+	if len(args) == 0 {
+		return runLambda()
+	}
+	switch args[0] {
+	case "lambda":
+		return runLambda()
+	case "serve":
+		return serve(args[1:])
+	case "render":
+		return render(args[1:])
+	case "validate":
+		return validate(args[1:])
+	case "schema":
+		return printSchema(args[1:])
+	case "help", "-h", "--help":
+		fmt.Fprint(os.Stderr, usage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command: %s\n%s", args[0], usage)
+	}
+}
+
+const usage = `lambuild - Lambda => CodeBuild
+
+Usage:
+  lambuild [lambda]   run as a Lambda Function (default)
+  lambuild serve      run as a HTTP server
+  lambuild render     print CodeBuild's build inputs of a webhook payload without calling AWS API
+  lambuild validate   validate configuration files (default: lambuild.yaml)
+  lambuild schema     print JSON Schema of lambuild.yaml or the Lambda Function's configuration
+  lambuild help       show this help
+`
+
+func runLambda() error {
 	ctx := context.Background()
 	handler := lmb.Handler{}
 	if err := initializer.InitializeHandler(ctx, &handler); err != nil {
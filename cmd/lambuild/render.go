@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/google/go-github/v37/github"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+	gh "github.com/suzuki-shunsuke/lambuild/pkg/github"
+	lmb "github.com/suzuki-shunsuke/lambuild/pkg/lambda"
+	"gopkg.in/yaml.v2"
+)
+
+type renderParam struct {
+	Event         string
+	PayloadPath   string
+	ConfigPath    string
+	Dir           string
+	File          string
+	Format        string
+	PRFilesPath   string
+	PushFilesPath string
+	AWSAccountID  string
+}
+
+func render(args []string) error {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	param := renderParam{}
+	flags.StringVar(&param.Event, "event", "", "GitHub Webhook event name. push or pull_request")
+	flags.StringVar(&param.PayloadPath, "payload", "", "the file path to GitHub Webhook payload JSON")
+	flags.StringVar(&param.ConfigPath, "config", "", "the file path to the Lambda Function's configuration. If this isn't specified, a repository and a hook which match any events are used")
+	flags.StringVar(&param.Dir, "dir", ".", "the local checkout directory of the repository")
+	flags.StringVar(&param.File, "file", "", "the relative path to lambuild.yaml from -dir. If this is specified, the hook's config is ignored")
+	flags.StringVar(&param.Format, "format", "yaml", "the output format. yaml or json")
+	flags.StringVar(&param.PRFilesPath, "pr-files", "", "the file path to the pull request files JSON, which is the response of GitHub API 'List pull requests files'")
+	flags.StringVar(&param.PushFilesPath, "push-files", "", "the file path to the push files JSON, which is `files` of the response of GitHub API 'Compare two commits'")
+	flags.StringVar(&param.AWSAccountID, "aws-account-id", "", "AWS Account ID")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("parse command line arguments: %w", err)
+	}
+	if param.Event == "" {
+		return errors.New("-event is required")
+	}
+	if param.PayloadPath == "" {
+		return errors.New("-payload is required")
+	}
+	if param.Format != "yaml" && param.Format != "json" {
+		return errors.New("-format must be either yaml or json: " + param.Format)
+	}
+
+	ctx := context.Background()
+	handler, event, err := newRenderHandler(param)
+	if err != nil {
+		return err
+	}
+	inputs, err := handler.Render(ctx, event, param.File)
+	if err != nil {
+		return fmt.Errorf("render build inputs: %w", err)
+	}
+	return outputRenderedInputs(os.Stdout, inputs, param.Format)
+}
+
+func newRenderHandler(param renderParam) (*lmb.Handler, domain.Event, error) {
+	event := domain.Event{
+		Headers: domain.Headers{
+			Event: param.Event,
+		},
+	}
+	payload, err := ioutil.ReadFile(param.PayloadPath)
+	if err != nil {
+		return nil, event, fmt.Errorf("read a payload file: %w", err)
+	}
+	event.Body = string(payload)
+	body, err := github.ParseWebHook(param.Event, payload)
+	if err != nil {
+		return nil, event, fmt.Errorf("parse a payload: %w", err)
+	}
+
+	local := &gh.Local{
+		Dir:    param.Dir,
+		Stderr: os.Stderr,
+	}
+	var repoFullName string
+	switch ev := body.(type) {
+	case *github.PushEvent:
+		repoFullName = ev.GetRepo().GetFullName()
+		local.Commit = &github.Commit{
+			SHA:     ev.GetHeadCommit().ID,
+			Message: ev.GetHeadCommit().Message,
+		}
+	case *github.PullRequestEvent:
+		repoFullName = ev.GetRepo().GetFullName()
+		local.PR = ev.GetPullRequest()
+	}
+	if param.PRFilesPath != "" {
+		b, err := ioutil.ReadFile(param.PRFilesPath)
+		if err != nil {
+			return nil, event, fmt.Errorf("read a pull request files: %w", err)
+		}
+		files := []*github.CommitFile{}
+		if err := json.Unmarshal(b, &files); err != nil {
+			return nil, event, fmt.Errorf("parse pull request files as JSON: %w", err)
+		}
+		local.PRFiles = files
+	}
+	if param.PushFilesPath != "" {
+		b, err := ioutil.ReadFile(param.PushFilesPath)
+		if err != nil {
+			return nil, event, fmt.Errorf("read a push files: %w", err)
+		}
+		files := []*github.CommitFile{}
+		if err := json.Unmarshal(b, &files); err != nil {
+			return nil, event, fmt.Errorf("parse push files as JSON: %w", err)
+		}
+		local.PushFiles = files
+	}
+
+	cfg := config.Config{}
+	if param.ConfigPath == "" {
+		cfg.Repositories = []config.Repository{
+			{
+				Name:  repoFullName,
+				Hooks: []config.Hook{{}},
+			},
+		}
+	} else {
+		b, err := ioutil.ReadFile(param.ConfigPath)
+		if err != nil {
+			return nil, event, fmt.Errorf("read a configuration file: %w", err)
+		}
+		if err := yaml.Unmarshal(b, &cfg); err != nil {
+			return nil, event, fmt.Errorf("parse a configuration file as YAML: %w", err)
+		}
+		if err := cfg.CompileTemplates(); err != nil {
+			return nil, event, fmt.Errorf("compile templates in the configuration file: %w", err)
+		}
+	}
+
+	return &lmb.Handler{
+		Config:       cfg,
+		GitHub:       local,
+		AWSAccountID: param.AWSAccountID,
+	}, event, nil
+}
+
+// outputRenderedInputs outputs build inputs as YAML or JSON.
+// Build inputs are converted to JSON once to remove null fields of AWS SDK's structs.
+func outputRenderedInputs(w io.Writer, inputs []lmb.RenderedInput, format string) error {
+	b, err := json.Marshal(inputs)
+	if err != nil {
+		return fmt.Errorf("marshal build inputs as JSON: %w", err)
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return fmt.Errorf("unmarshal build inputs as JSON: %w", err)
+	}
+	v = removeNull(v)
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Errorf("output build inputs as JSON: %w", err)
+		}
+		return nil
+	}
+	if err := yaml.NewEncoder(w).Encode(v); err != nil {
+		return fmt.Errorf("output build inputs as YAML: %w", err)
+	}
+	return nil
+}
+
+func removeNull(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, a := range val {
+			if a == nil {
+				continue
+			}
+			m[k] = removeNull(a)
+		}
+		return m
+	case []interface{}:
+		arr := make([]interface{}, len(val))
+		for i, a := range val {
+			arr[i] = removeNull(a)
+		}
+		return arr
+	default:
+		return v
+	}
+}
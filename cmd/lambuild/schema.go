@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/suzuki-shunsuke/lambuild/pkg/schema"
+)
+
+const schemaUsage = "usage: lambuild schema (lambuild|config)"
+
+func printSchema(args []string) error {
+	if len(args) != 1 {
+		return errors.New(schemaUsage)
+	}
+	var s *schema.Schema
+	var err error
+	switch args[0] {
+	case "lambuild":
+		s, err = schema.Buildspec()
+	case "config":
+		s, err = schema.Config()
+	default:
+		return fmt.Errorf("unknown schema: %s\n%s", args[0], schemaUsage)
+	}
+	if err != nil {
+		return fmt.Errorf("generate JSON Schema: %w", err)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s); err != nil {
+		return fmt.Errorf("encode JSON Schema as JSON: %w", err)
+	}
+	return nil
+}
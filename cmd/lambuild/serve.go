@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/suzuki-shunsuke/lambuild/pkg/initializer"
+	lmb "github.com/suzuki-shunsuke/lambuild/pkg/lambda"
+	"github.com/suzuki-shunsuke/lambuild/pkg/server"
+)
+
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	param := server.Param{}
+	flags.StringVar(&param.Addr, "addr", getEnv("LAMBUILD_ADDR", ":8080"), "the TCP address for the server to listen on")
+	flags.StringVar(&param.WebhookPath, "webhook-path", getEnv("LAMBUILD_WEBHOOK_PATH", "/lambuild"), "the path of the webhook endpoint")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("parse command line arguments: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	handler := lmb.Handler{}
+	if err := initializer.InitializeHandler(ctx, &handler); err != nil {
+		return fmt.Errorf("initialize the handler: %w", err)
+	}
+	return server.Run(ctx, &handler, param) //nolint:wrapcheck
+}
+
+func getEnv(key, defaultValue string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return defaultValue
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/suzuki-shunsuke/lambuild/pkg/validator"
+)
+
+var errInvalidConfig = errors.New("configuration files are invalid")
+
+func validate(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("parse command line arguments: %w", err)
+	}
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"lambuild.yaml"}
+	}
+	files, err := findConfigFiles(paths)
+	if err != nil {
+		return err
+	}
+	return validateFiles(os.Stdout, files)
+}
+
+// findConfigFiles returns configuration files.
+// If a directory is passed, .yaml and .yml files in the directory are returned.
+func findConfigFiles(paths []string) ([]string, error) {
+	files := []string{}
+	for _, p := range paths {
+		finfo, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("get a file information (%s): %w", p, err)
+		}
+		if !finfo.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		entries, err := ioutil.ReadDir(p)
+		if err != nil {
+			return nil, fmt.Errorf("read a directory (%s): %w", p, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			ext := filepath.Ext(entry.Name())
+			if ext != ".yaml" && ext != ".yml" {
+				continue
+			}
+			files = append(files, filepath.Join(p, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+func validateFiles(out io.Writer, files []string) error {
+	invalid := false
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read a file (%s): %w", file, err)
+		}
+		issues, err := validator.Validate(file, content)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			invalid = true
+			continue
+		}
+		for _, issue := range issues {
+			fmt.Fprintln(out, issue)
+		}
+		if len(issues) != 0 {
+			invalid = true
+		}
+	}
+	if invalid {
+		return errInvalidConfig
+	}
+	return nil
+}
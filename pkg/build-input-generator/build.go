@@ -50,32 +50,23 @@ func handleBuildItem(data *domain.Data, buildspec bspec.Buildspec, item bspec.It
 		}
 	}
 
-	envMap := map[string]string{}
-	for k, prog := range buildspec.Lambuild.Env.Variables {
-		s, err := prog.Run(param)
-		if err != nil {
-			return build, fmt.Errorf("evaluate an expression: %w", err)
-		}
-		envMap[k] = s
+	f, err := data.MatchPaths(item.Paths, item.PathsIgnore)
+	if err != nil {
+		return build, fmt.Errorf("filter item by item.paths and item.paths-ignore: %w", err)
 	}
-
-	for k, prog := range item.Env.Variables {
-		s, err := prog.Run(param)
-		if err != nil {
-			return build, fmt.Errorf("evaluate an expression: %w", err)
-		}
-		envMap[k] = s
+	if !f {
+		return build, nil
 	}
 
-	envs := make([]*codebuild.EnvironmentVariable, 0, len(envMap))
-	for k, v := range envMap {
-		envs = append(envs, &codebuild.EnvironmentVariable{
-			Name:  aws.String(k),
-			Value: aws.String(v),
-		})
+	envMap := map[string]*codebuild.EnvironmentVariable{}
+	if err := evaluateEnvVars(param, buildspec.Lambuild.Env.Variables, envMap); err != nil {
+		return build, err
 	}
-	if len(envs) != 0 {
-		build.EnvironmentVariablesOverride = envs
+	if err := evaluateEnvVars(param, item.Env.Variables, envMap); err != nil {
+		return build, err
+	}
+	if len(envMap) != 0 {
+		build.EnvironmentVariablesOverride = toEnvironmentVariables(envMap)
 	}
 
 	if !item.BuildStatusContext.Empty() {
@@ -126,6 +117,8 @@ func handleBuildItem(data *domain.Data, buildspec bspec.Buildspec, item bspec.It
 		build.PrivilegedModeOverride = buildspec.Lambuild.PrivilegedMode
 	}
 
+	newOverrides(buildspec.Lambuild).merge(item).setStartBuildInput(&build)
+
 	builtContent, err := buildspec.ToYAML(param)
 	if err != nil {
 		return build, fmt.Errorf("marshal a buildspec: %w", err)
@@ -29,6 +29,16 @@ func GenerateInput(logE *logrus.Entry, buildStatusContext template.Template, dat
 		}
 	}
 
+	f, err := data.MatchPaths(buildspec.Lambuild.Paths, buildspec.Lambuild.PathsIgnore)
+	if err != nil {
+		return buildInput, fmt.Errorf("filter buildspec by lambuild.paths and lambuild.paths-ignore: %w", err)
+	}
+	if !f {
+		return domain.BuildInput{
+			Empty: true,
+		}, nil
+	}
+
 	if len(buildspec.Batch.BuildGraph) != 0 {
 		logE.Debug("handling build-graph")
 		if err := handleGraph(buildStatusContext, &buildInput, logE, data, buildspec); err != nil {
@@ -57,46 +67,59 @@ func GenerateInput(logE *logrus.Entry, buildStatusContext template.Template, dat
 }
 
 func setEnvsToStartBuildInput(input *codebuild.StartBuildInput, data *domain.Data, lambuild bspec.Lambuild, envVars map[string]string) error {
-	envMap := make(map[string]string, len(envVars)+len(lambuild.Env.Variables))
-	for k, prog := range lambuild.Env.Variables {
-		s, err := prog.Run(data.Convert())
-		if err != nil {
-			return fmt.Errorf("evaluate an expression: %w", err)
-		}
-		envMap[k] = s
+	envMap := make(map[string]*codebuild.EnvironmentVariable, len(envVars)+len(lambuild.Env.Variables))
+	if err := evaluateEnvVars(data.Convert(), lambuild.Env.Variables, envMap); err != nil {
+		return err
 	}
 	for k, v := range envVars {
-		envMap[k] = v
+		envMap[k] = newEnvironmentVariable(k, v, bspec.EnvTypePlaintext)
 	}
 
 	if len(envMap) == 0 {
 		return nil
 	}
-
-	envs := make([]*codebuild.EnvironmentVariable, 0, len(envMap))
-	for k, v := range envMap {
-		envs = append(envs, &codebuild.EnvironmentVariable{
-			Name:  aws.String(k),
-			Value: aws.String(v),
-		})
-	}
-	input.EnvironmentVariablesOverride = envs
+	input.EnvironmentVariablesOverride = toEnvironmentVariables(envMap)
 	return nil
 }
 
 func getLambuildEnvVars(data *domain.Data, lambuild bspec.Lambuild) ([]*codebuild.EnvironmentVariable, error) {
-	envs := make([]*codebuild.EnvironmentVariable, 0, len(lambuild.Env.Variables))
-	for k, prog := range lambuild.Env.Variables {
-		s, err := prog.Run(data.Convert())
+	envMap := make(map[string]*codebuild.EnvironmentVariable, len(lambuild.Env.Variables))
+	if err := evaluateEnvVars(data.Convert(), lambuild.Env.Variables, envMap); err != nil {
+		return nil, err
+	}
+	return toEnvironmentVariables(envMap), nil
+}
+
+// evaluateEnvVars evaluates environment variables and sets them to envMap.
+func evaluateEnvVars(param interface{}, envVars map[string]bspec.EnvVar, envMap map[string]*codebuild.EnvironmentVariable) error {
+	for k, envVar := range envVars {
+		s, err := envVar.Run(param)
 		if err != nil {
-			return nil, fmt.Errorf("evaluate an expression: %w", err)
+			return fmt.Errorf("evaluate an expression: %w", err)
 		}
-		envs = append(envs, &codebuild.EnvironmentVariable{
-			Name:  aws.String(k),
-			Value: aws.String(s),
-		})
+		envMap[k] = newEnvironmentVariable(k, s, envVar.Type)
 	}
-	return envs, nil
+	return nil
+}
+
+func newEnvironmentVariable(name, value, typ string) *codebuild.EnvironmentVariable {
+	envVar := &codebuild.EnvironmentVariable{
+		Name:  aws.String(name),
+		Value: aws.String(value),
+	}
+	// PLAINTEXT is the default type, so it is omitted
+	if typ != "" && typ != bspec.EnvTypePlaintext {
+		envVar.Type = aws.String(typ)
+	}
+	return envVar
+}
+
+func toEnvironmentVariables(envMap map[string]*codebuild.EnvironmentVariable) []*codebuild.EnvironmentVariable {
+	envs := make([]*codebuild.EnvironmentVariable, 0, len(envMap))
+	for _, envVar := range envMap {
+		envs = append(envs, envVar)
+	}
+	return envs
 }
 
 func setBatchBuildInput(input *codebuild.StartBuildBatchInput, buildspec bspec.Buildspec, data *domain.Data) error {
@@ -114,5 +137,22 @@ func setBatchBuildInput(input *codebuild.StartBuildBatchInput, buildspec bspec.B
 	}
 	input.BuildspecOverride = aws.String(string(s))
 
+	lambuild := buildspec.Lambuild
+	if lambuild.Image != "" {
+		input.ImageOverride = aws.String(lambuild.Image)
+	}
+	if lambuild.ComputeType != "" {
+		input.ComputeTypeOverride = aws.String(lambuild.ComputeType)
+	}
+	if lambuild.EnvironmentType != "" {
+		input.EnvironmentTypeOverride = aws.String(lambuild.EnvironmentType)
+	}
+	input.DebugSessionEnabled = lambuild.DebugSession
+	input.GitCloneDepthOverride = lambuild.GitCloneDepth
+	input.PrivilegedModeOverride = lambuild.PrivilegedMode
+	input.BuildBatchConfigOverride = toProjectBuildBatchConfig(lambuild.BuildBatchConfig)
+
+	newOverrides(lambuild).setStartBuildBatchInput(input)
+
 	return nil
 }
@@ -28,8 +28,8 @@ func Test_setBatchBuildInput(t *testing.T) {
 			buildspec: bspec.Buildspec{
 				Lambuild: bspec.Lambuild{
 					Env: bspec.LambuildEnv{
-						Variables: map[string]expr.String{
-							"FOO": expr.NewStringForTest(t, `"BAR"`),
+						Variables: map[string]bspec.EnvVar{
+							"FOO": bspec.NewEnvVar(expr.NewStringForTest(t, `"BAR"`)),
 						},
 					},
 				},
@@ -43,6 +43,39 @@ func Test_setBatchBuildInput(t *testing.T) {
 				},
 			},
 		},
+		{
+			title: "overrides",
+			buildspec: bspec.Buildspec{
+				Lambuild: bspec.Lambuild{
+					Image:          "aws/codebuild/standard:5.0",
+					ComputeType:    "BUILD_GENERAL1_MEDIUM",
+					PrivilegedMode: aws.Bool(true),
+					GitCloneDepth:  aws.Int64(1),
+					BuildBatchConfig: &bspec.BuildBatchConfig{
+						CombineArtifacts: aws.Bool(true),
+						TimeoutInMinutes: aws.Int64(60),
+						Restrictions: &bspec.BatchRestrictions{
+							ComputeTypesAllowed:  []string{"BUILD_GENERAL1_SMALL"},
+							MaximumBuildsAllowed: aws.Int64(10),
+						},
+					},
+				},
+			},
+			exp: codebuild.StartBuildBatchInput{
+				ImageOverride:          aws.String("aws/codebuild/standard:5.0"),
+				ComputeTypeOverride:    aws.String("BUILD_GENERAL1_MEDIUM"),
+				PrivilegedModeOverride: aws.Bool(true),
+				GitCloneDepthOverride:  aws.Int64(1),
+				BuildBatchConfigOverride: &codebuild.ProjectBuildBatchConfig{
+					CombineArtifacts: aws.Bool(true),
+					TimeoutInMins:    aws.Int64(60),
+					Restrictions: &codebuild.BatchRestrictions{
+						ComputeTypesAllowed:  aws.StringSlice([]string{"BUILD_GENERAL1_SMALL"}),
+						MaximumBuildsAllowed: aws.Int64(10),
+					},
+				},
+			},
+		},
 	}
 	for _, d := range data {
 		d := d
@@ -76,18 +76,26 @@ func extractGraphByDependency(identifiers map[string]bspec.GraphElement, logE *l
 
 func extractGraphByIf(data *domain.Data, allElems []bspec.GraphElement, identifiers map[string]bspec.GraphElement) error {
 	for _, elem := range allElems {
-		if elem.If.Empty() {
-			identifiers[elem.Identifier] = elem
-			continue
+		if !elem.If.Empty() {
+			f, err := elem.If.Run(data.Convert())
+			if err != nil {
+				return fmt.Errorf("evaluate an expression: %w", err)
+			}
+			if !f {
+				continue
+			}
 		}
-		f, err := elem.If.Run(data.Convert())
+		f, err := data.MatchPaths(elem.Paths, elem.PathsIgnore)
 		if err != nil {
-			return fmt.Errorf("evaluate an expression: %w", err)
+			return fmt.Errorf("filter a graph element by paths and paths-ignore (%s): %w", elem.Identifier, err)
 		}
 		if !f {
 			continue
 		}
+		// remove lambuild's fields because CodeBuild doesn't support them
 		elem.If = expr.Bool{}
+		elem.Paths = nil
+		elem.PathsIgnore = nil
 		identifiers[elem.Identifier] = elem
 	}
 	return nil
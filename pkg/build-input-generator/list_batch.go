@@ -84,18 +84,26 @@ func setListBuildInput(input *codebuild.StartBuildInput, contx template.Template
 func extractBuildList(data *domain.Data, allElems []bspec.ListElement) ([]bspec.ListElement, error) {
 	listElems := []bspec.ListElement{}
 	for _, listElem := range allElems {
-		if listElem.If.Empty() {
-			listElems = append(listElems, listElem)
-			continue
+		if !listElem.If.Empty() {
+			f, err := listElem.If.Run(data.Convert())
+			if err != nil {
+				return nil, fmt.Errorf("evaluate an expression: %w", err)
+			}
+			if !f {
+				continue
+			}
 		}
-		f, err := listElem.If.Run(data.Convert())
+		f, err := data.MatchPaths(listElem.Paths, listElem.PathsIgnore)
 		if err != nil {
-			return nil, fmt.Errorf("evaluate an expression: %w", err)
+			return nil, fmt.Errorf("filter a list element by paths and paths-ignore (%s): %w", listElem.Identifier, err)
 		}
 		if !f {
 			continue
 		}
+		// remove lambuild's fields because CodeBuild doesn't support them
 		listElem.If = expr.Bool{}
+		listElem.Paths = nil
+		listElem.PathsIgnore = nil
 		listElems = append(listElems, listElem)
 	}
 	return listElems, nil
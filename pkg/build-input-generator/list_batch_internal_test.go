@@ -7,9 +7,11 @@ import (
 	"github.com/aws/aws-sdk-go/service/codebuild"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-github/v37/github"
 	"github.com/sirupsen/logrus"
 	bspec "github.com/suzuki-shunsuke/lambuild/pkg/buildspec"
 	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+	gh "github.com/suzuki-shunsuke/lambuild/pkg/github"
 	"github.com/suzuki-shunsuke/lambuild/pkg/template"
 	"gopkg.in/yaml.v2"
 )
@@ -175,6 +177,36 @@ func Test_extractBuildList(t *testing.T) {
 `,
 			exp: []string{"deploy", "build"},
 		},
+		{
+			title: "paths",
+			input: `
+- identifier: foo
+  paths:
+  - "foo/**"
+- identifier: bar
+  paths:
+  - "bar/**"
+- identifier: docs
+  paths-ignore:
+  - "**/*.md"
+`,
+			data: domain.Data{
+				Event: domain.Event{
+					Payload: &github.PushEvent{
+						Before: github.String("b15a8a4e1d14d1a8ed2e4a8e1bd7a8a8b8a8a8a8"),
+						After:  github.String("3ed5a8ad1d4bb4b1e4a1ab2d68aa5d1cd1d2a5c6"),
+					},
+				},
+				Push: domain.NewPush(),
+				GitHub: &gh.Local{
+					PushFiles: []*github.CommitFile{
+						{Filename: github.String("foo/README.md")},
+						{Filename: github.String("foo/main.go")},
+					},
+				},
+			},
+			exp: []string{"foo", "docs"},
+		},
 	}
 	for _, d := range data {
 		d := d
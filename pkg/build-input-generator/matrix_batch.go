@@ -146,29 +146,18 @@ func setMatrixBuildInput(data *domain.Data, buildStatusContext template.Template
 		input.ComputeTypeOverride = aws.String(dynamic.Env.ComputeType[0].(string))
 	}
 
-	envMap := make(map[string]string, len(lambuild.Env.Variables))
-	for k, prog := range lambuild.Env.Variables {
-		s, err := prog.Run(data.Convert())
-		if err != nil {
-			return fmt.Errorf("evaluate an expression: %w", err)
-		}
-		envMap[k] = s
+	envMap := make(map[string]*codebuild.EnvironmentVariable, len(lambuild.Env.Variables))
+	if err := evaluateEnvVars(data.Convert(), lambuild.Env.Variables, envMap); err != nil {
+		return err
 	}
 	if getSizeOfEnvVars(dynamic.Env.Variables) != 0 {
 		for k, v := range dynamic.Env.Variables {
-			envMap[k] = v[0].(string) //nolint:forcetypeassert
+			envMap[k] = newEnvironmentVariable(k, v[0].(string), bspec.EnvTypePlaintext) //nolint:forcetypeassert
 		}
 	}
 
 	if len(envMap) != 0 {
-		envs := make([]*codebuild.EnvironmentVariable, 0, len(envMap))
-		for k, v := range envMap {
-			envs = append(envs, &codebuild.EnvironmentVariable{
-				Name:  aws.String(k),
-				Value: aws.String(v),
-			})
-		}
-		input.EnvironmentVariablesOverride = envs
+		input.EnvironmentVariablesOverride = toEnvironmentVariables(envMap)
 	}
 
 	return nil
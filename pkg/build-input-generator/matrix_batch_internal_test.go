@@ -11,7 +11,7 @@ import (
 	bspec "github.com/suzuki-shunsuke/lambuild/pkg/buildspec"
 	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
 	"github.com/suzuki-shunsuke/lambuild/pkg/expr"
-	"github.com/suzuki-shunsuke/lambuild/pkg/mutex"
+	"github.com/suzuki-shunsuke/lambuild/pkg/lazy"
 	"github.com/suzuki-shunsuke/lambuild/pkg/template"
 )
 
@@ -108,8 +108,8 @@ func Test_expression(t *testing.T) {
 			title: "normal",
 			data: domain.Data{
 				PullRequest: domain.PullRequest{
-					ChangedFileNames: mutex.NewStringList("modules/README.md"),
-					LabelNames:       mutex.NewStringList(""),
+					ChangedFileNames: lazy.NewWithValue([]string{"modules/README.md"}),
+					LabelNames:       lazy.NewWithValue([]string{""}),
 				},
 				Event: domain.Event{
 					Headers: domain.Headers{
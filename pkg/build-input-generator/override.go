@@ -0,0 +1,206 @@
+package generator
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+	bspec "github.com/suzuki-shunsuke/lambuild/pkg/buildspec"
+)
+
+// overrides are StartBuild and StartBuildBatch overrides which are configured in lambuild.yaml.
+type overrides struct {
+	ReportBuildStatus      *bool
+	TimeoutInMinutes       *int64
+	QueuedTimeoutInMinutes *int64
+	Cache                  *bspec.Cache
+	Artifacts              *bspec.Artifacts
+	LogsConfig             *bspec.LogsConfig
+	SecondarySources       []bspec.Source
+	EncryptionKey          string
+	SourceAuth             *bspec.SourceAuth
+}
+
+func newOverrides(lambuild bspec.Lambuild) overrides {
+	return overrides{
+		ReportBuildStatus:      lambuild.ReportBuildStatus,
+		TimeoutInMinutes:       lambuild.TimeoutInMinutes,
+		QueuedTimeoutInMinutes: lambuild.QueuedTimeoutInMinutes,
+		Cache:                  lambuild.Cache,
+		Artifacts:              lambuild.Artifacts,
+		LogsConfig:             lambuild.LogsConfig,
+		SecondarySources:       lambuild.SecondarySources,
+		EncryptionKey:          lambuild.EncryptionKey,
+		SourceAuth:             lambuild.SourceAuth,
+	}
+}
+
+// merge returns overrides which item's overrides take precedence over.
+func (o overrides) merge(item bspec.Item) overrides {
+	if item.ReportBuildStatus != nil {
+		o.ReportBuildStatus = item.ReportBuildStatus
+	}
+	if item.TimeoutInMinutes != nil {
+		o.TimeoutInMinutes = item.TimeoutInMinutes
+	}
+	if item.QueuedTimeoutInMinutes != nil {
+		o.QueuedTimeoutInMinutes = item.QueuedTimeoutInMinutes
+	}
+	if item.Cache != nil {
+		o.Cache = item.Cache
+	}
+	if item.Artifacts != nil {
+		o.Artifacts = item.Artifacts
+	}
+	if item.LogsConfig != nil {
+		o.LogsConfig = item.LogsConfig
+	}
+	if item.SecondarySources != nil {
+		o.SecondarySources = item.SecondarySources
+	}
+	if item.EncryptionKey != "" {
+		o.EncryptionKey = item.EncryptionKey
+	}
+	if item.SourceAuth != nil {
+		o.SourceAuth = item.SourceAuth
+	}
+	return o
+}
+
+func (o overrides) setStartBuildInput(input *codebuild.StartBuildInput) {
+	input.ReportBuildStatusOverride = o.ReportBuildStatus
+	input.TimeoutInMinutesOverride = o.TimeoutInMinutes
+	input.QueuedTimeoutInMinutesOverride = o.QueuedTimeoutInMinutes
+	input.CacheOverride = toProjectCache(o.Cache)
+	input.ArtifactsOverride = toProjectArtifacts(o.Artifacts)
+	input.LogsConfigOverride = toLogsConfig(o.LogsConfig)
+	input.SecondarySourcesOverride, input.SecondarySourcesVersionOverride = toSecondarySources(o.SecondarySources)
+	if o.EncryptionKey != "" {
+		input.EncryptionKeyOverride = aws.String(o.EncryptionKey)
+	}
+	input.SourceAuthOverride = toSourceAuth(o.SourceAuth)
+}
+
+func (o overrides) setStartBuildBatchInput(input *codebuild.StartBuildBatchInput) {
+	input.ReportBuildBatchStatusOverride = o.ReportBuildStatus
+	input.BuildTimeoutInMinutesOverride = o.TimeoutInMinutes
+	input.QueuedTimeoutInMinutesOverride = o.QueuedTimeoutInMinutes
+	input.CacheOverride = toProjectCache(o.Cache)
+	input.ArtifactsOverride = toProjectArtifacts(o.Artifacts)
+	input.LogsConfigOverride = toLogsConfig(o.LogsConfig)
+	input.SecondarySourcesOverride, input.SecondarySourcesVersionOverride = toSecondarySources(o.SecondarySources)
+	if o.EncryptionKey != "" {
+		input.EncryptionKeyOverride = aws.String(o.EncryptionKey)
+	}
+	input.SourceAuthOverride = toSourceAuth(o.SourceAuth)
+}
+
+// stringOrNil returns nil if s is empty, because CodeBuild API rejects some empty strings.
+func stringOrNil(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
+
+func toProjectCache(cache *bspec.Cache) *codebuild.ProjectCache {
+	if cache == nil {
+		return nil
+	}
+	return &codebuild.ProjectCache{
+		Type:     stringOrNil(cache.Type),
+		Location: stringOrNil(cache.Location),
+		Modes:    aws.StringSlice(cache.Modes),
+	}
+}
+
+func toProjectArtifacts(artifacts *bspec.Artifacts) *codebuild.ProjectArtifacts {
+	if artifacts == nil {
+		return nil
+	}
+	return &codebuild.ProjectArtifacts{
+		Type:                 stringOrNil(artifacts.Type),
+		Location:             stringOrNil(artifacts.Location),
+		Path:                 stringOrNil(artifacts.Path),
+		Name:                 stringOrNil(artifacts.Name),
+		NamespaceType:        stringOrNil(artifacts.NamespaceType),
+		Packaging:            stringOrNil(artifacts.Packaging),
+		EncryptionDisabled:   artifacts.EncryptionDisabled,
+		OverrideArtifactName: artifacts.OverrideArtifactName,
+		ArtifactIdentifier:   stringOrNil(artifacts.ArtifactIdentifier),
+	}
+}
+
+func toLogsConfig(logsConfig *bspec.LogsConfig) *codebuild.LogsConfig {
+	if logsConfig == nil {
+		return nil
+	}
+	cfg := &codebuild.LogsConfig{}
+	if cw := logsConfig.CloudWatchLogs; cw != nil {
+		cfg.CloudWatchLogs = &codebuild.CloudWatchLogsConfig{
+			Status:     stringOrNil(cw.Status),
+			GroupName:  stringOrNil(cw.GroupName),
+			StreamName: stringOrNil(cw.StreamName),
+		}
+	}
+	if s3 := logsConfig.S3Logs; s3 != nil {
+		cfg.S3Logs = &codebuild.S3LogsConfig{
+			Status:             stringOrNil(s3.Status),
+			Location:           stringOrNil(s3.Location),
+			EncryptionDisabled: s3.EncryptionDisabled,
+		}
+	}
+	return cfg
+}
+
+// toSecondarySources returns secondary sources and their versions.
+func toSecondarySources(srcs []bspec.Source) ([]*codebuild.ProjectSource, []*codebuild.ProjectSourceVersion) {
+	if len(srcs) == 0 {
+		return nil, nil
+	}
+	sources := make([]*codebuild.ProjectSource, len(srcs))
+	var versions []*codebuild.ProjectSourceVersion
+	for i, src := range srcs {
+		sources[i] = &codebuild.ProjectSource{
+			Type:              stringOrNil(src.Type),
+			Location:          stringOrNil(src.Location),
+			SourceIdentifier:  stringOrNil(src.SourceIdentifier),
+			GitCloneDepth:     src.GitCloneDepth,
+			Buildspec:         stringOrNil(src.Buildspec),
+			ReportBuildStatus: src.ReportBuildStatus,
+			InsecureSsl:       src.InsecureSSL,
+		}
+		if src.SourceVersion != "" {
+			versions = append(versions, &codebuild.ProjectSourceVersion{
+				SourceIdentifier: stringOrNil(src.SourceIdentifier),
+				SourceVersion:    aws.String(src.SourceVersion),
+			})
+		}
+	}
+	return sources, versions
+}
+
+func toSourceAuth(auth *bspec.SourceAuth) *codebuild.SourceAuth {
+	if auth == nil {
+		return nil
+	}
+	return &codebuild.SourceAuth{
+		Type:     stringOrNil(auth.Type),
+		Resource: stringOrNil(auth.Resource),
+	}
+}
+
+func toProjectBuildBatchConfig(cfg *bspec.BuildBatchConfig) *codebuild.ProjectBuildBatchConfig {
+	if cfg == nil {
+		return nil
+	}
+	batchConfig := &codebuild.ProjectBuildBatchConfig{
+		CombineArtifacts: cfg.CombineArtifacts,
+		TimeoutInMins:    cfg.TimeoutInMinutes,
+	}
+	if r := cfg.Restrictions; r != nil {
+		batchConfig.Restrictions = &codebuild.BatchRestrictions{
+			ComputeTypesAllowed:  aws.StringSlice(r.ComputeTypesAllowed),
+			MaximumBuildsAllowed: r.MaximumBuildsAllowed,
+		}
+	}
+	return batchConfig
+}
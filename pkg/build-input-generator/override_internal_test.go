@@ -0,0 +1,107 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+	"github.com/google/go-cmp/cmp"
+	bspec "github.com/suzuki-shunsuke/lambuild/pkg/buildspec"
+)
+
+func Test_overridesSetStartBuildInput(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title    string
+		lambuild bspec.Lambuild
+		item     bspec.Item
+		exp      codebuild.StartBuildInput
+	}{
+		{
+			title: "no override",
+			exp:   codebuild.StartBuildInput{},
+		},
+		{
+			title: "lambuild",
+			lambuild: bspec.Lambuild{
+				ReportBuildStatus: aws.Bool(false),
+				TimeoutInMinutes:  aws.Int64(30),
+				Cache: &bspec.Cache{
+					Type:  "LOCAL",
+					Modes: []string{"LOCAL_DOCKER_LAYER_CACHE"},
+				},
+				SecondarySources: []bspec.Source{
+					{
+						Type:             "GITHUB",
+						Location:         "https://github.com/suzuki-shunsuke/foo",
+						SourceIdentifier: "foo",
+						SourceVersion:    "main",
+					},
+				},
+				EncryptionKey: "alias/lambuild",
+			},
+			exp: codebuild.StartBuildInput{
+				ReportBuildStatusOverride: aws.Bool(false),
+				TimeoutInMinutesOverride:  aws.Int64(30),
+				CacheOverride: &codebuild.ProjectCache{
+					Type:  aws.String("LOCAL"),
+					Modes: aws.StringSlice([]string{"LOCAL_DOCKER_LAYER_CACHE"}),
+				},
+				SecondarySourcesOverride: []*codebuild.ProjectSource{
+					{
+						Type:             aws.String("GITHUB"),
+						Location:         aws.String("https://github.com/suzuki-shunsuke/foo"),
+						SourceIdentifier: aws.String("foo"),
+					},
+				},
+				SecondarySourcesVersionOverride: []*codebuild.ProjectSourceVersion{
+					{
+						SourceIdentifier: aws.String("foo"),
+						SourceVersion:    aws.String("main"),
+					},
+				},
+				EncryptionKeyOverride: aws.String("alias/lambuild"),
+			},
+		},
+		{
+			title: "item takes precedence over lambuild",
+			lambuild: bspec.Lambuild{
+				ReportBuildStatus: aws.Bool(false),
+				TimeoutInMinutes:  aws.Int64(30),
+				EncryptionKey:     "alias/lambuild",
+			},
+			item: bspec.Item{
+				ReportBuildStatus: aws.Bool(true),
+				LogsConfig: &bspec.LogsConfig{
+					CloudWatchLogs: &bspec.CloudWatchLogs{
+						Status:    "ENABLED",
+						GroupName: "/lambuild/foo",
+					},
+				},
+				EncryptionKey: "alias/foo",
+			},
+			exp: codebuild.StartBuildInput{
+				ReportBuildStatusOverride: aws.Bool(true),
+				TimeoutInMinutesOverride:  aws.Int64(30),
+				LogsConfigOverride: &codebuild.LogsConfig{
+					CloudWatchLogs: &codebuild.CloudWatchLogsConfig{
+						Status:    aws.String("ENABLED"),
+						GroupName: aws.String("/lambuild/foo"),
+					},
+				},
+				EncryptionKeyOverride: aws.String("alias/foo"),
+			},
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			input := codebuild.StartBuildInput{}
+			newOverrides(d.lambuild).merge(d.item).setStartBuildInput(&input)
+			if diff := cmp.Diff(input, d.exp); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
@@ -4,10 +4,15 @@ import (
 	"fmt"
 
 	"github.com/suzuki-shunsuke/lambuild/pkg/expr"
+	"github.com/suzuki-shunsuke/lambuild/pkg/pathfilter"
 	"github.com/suzuki-shunsuke/lambuild/pkg/template"
 	"gopkg.in/yaml.v2"
 )
 
+// CodeBuildKeys are top level keys of CodeBuild's buildspec which lambuild passes to CodeBuild as they are.
+// https://docs.aws.amazon.com/codebuild/latest/userguide/build-spec-ref.html
+var CodeBuildKeys = []string{"version", "run-as", "env", "proxy", "reports", "artifacts", "cache"} //nolint:gochecknoglobals
+
 type Buildspec struct {
 	Batch    Batch                  `yaml:",omitempty"`
 	Lambuild Lambuild               `yaml:",omitempty"`
@@ -59,8 +64,20 @@ type Lambuild struct {
 	ReportBuildStatus  *bool  `yaml:"report-build-status"`
 	// It is danger to allow to override Service Role
 	// So lambuild doesn't support to override Service Role
-	Items []Item
-	If    expr.Bool
+	Items                  []Item
+	If                     expr.Bool
+	Paths                  pathfilter.Patterns
+	PathsIgnore            pathfilter.Patterns `yaml:"paths-ignore"`
+	TimeoutInMinutes       *int64              `yaml:"timeout-in-minutes"`
+	QueuedTimeoutInMinutes *int64              `yaml:"queued-timeout-in-minutes"`
+	Cache                  *Cache
+	Artifacts              *Artifacts
+	LogsConfig             *LogsConfig `yaml:"logs-config"`
+	SecondarySources       []Source    `yaml:"secondary-sources"`
+	EncryptionKey          string      `yaml:"encryption-key"`
+	SourceAuth             *SourceAuth `yaml:"source-auth"`
+	// BuildBatchConfig is used only for batch builds
+	BuildBatchConfig *BuildBatchConfig `yaml:"build-batch-config"`
 }
 
 type Item struct {
@@ -71,10 +88,22 @@ type Item struct {
 	ComputeType        string `yaml:"compute-type"`
 	EnvironmentType    string `yaml:"environment-type"`
 	Param              map[string]interface{}
+	Paths              pathfilter.Patterns
+	PathsIgnore        pathfilter.Patterns `yaml:"paths-ignore"`
+	// The following fields override Lambuild's ones
+	ReportBuildStatus      *bool  `yaml:"report-build-status"`
+	TimeoutInMinutes       *int64 `yaml:"timeout-in-minutes"`
+	QueuedTimeoutInMinutes *int64 `yaml:"queued-timeout-in-minutes"`
+	Cache                  *Cache
+	Artifacts              *Artifacts
+	LogsConfig             *LogsConfig `yaml:"logs-config"`
+	SecondarySources       []Source    `yaml:"secondary-sources"`
+	EncryptionKey          string      `yaml:"encryption-key"`
+	SourceAuth             *SourceAuth `yaml:"source-auth"`
 }
 
 type LambuildEnv struct {
-	Variables map[string]expr.String
+	Variables map[string]EnvVar
 }
 
 type Batch struct {
@@ -0,0 +1,79 @@
+package buildspec
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/suzuki-shunsuke/lambuild/pkg/expr"
+)
+
+const (
+	EnvTypePlaintext      = "PLAINTEXT"
+	EnvTypeParameterStore = "PARAMETER_STORE"
+	EnvTypeSecretsManager = "SECRETS_MANAGER"
+)
+
+// EnvVar is an environment variable of lambuild.env.variables.
+// EnvVar is either a string expression or a map which has a string expression `value` and `type`.
+// If the type is PARAMETER_STORE or SECRETS_MANAGER, the evaluated value is the name of the parameter or secret.
+type EnvVar struct {
+	Value expr.String
+	Type  string
+}
+
+// EnvTypes returns types of environment variables.
+func EnvTypes() []string {
+	return []string{EnvTypePlaintext, EnvTypeParameterStore, EnvTypeSecretsManager}
+}
+
+func NewEnvVar(value expr.String) EnvVar {
+	return EnvVar{
+		Value: value,
+		Type:  EnvTypePlaintext,
+	}
+}
+
+func (envVar *EnvVar) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		value, err := expr.NewString(s)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		*envVar = NewEnvVar(value)
+		return nil
+	}
+	a := struct {
+		Value expr.String
+		Type  string
+	}{}
+	if err := unmarshal(&a); err != nil {
+		return err
+	}
+	if a.Value.Empty() {
+		return errors.New("the environment variable's value is required")
+	}
+	if a.Type == "" {
+		a.Type = EnvTypePlaintext
+	}
+	if !validEnvType(a.Type) {
+		return fmt.Errorf("the environment variable's type must be one of PLAINTEXT, PARAMETER_STORE, and SECRETS_MANAGER: %s", a.Type)
+	}
+	envVar.Value = a.Value
+	envVar.Type = a.Type
+	return nil
+}
+
+// Run evaluates the value.
+func (envVar *EnvVar) Run(param interface{}) (string, error) {
+	return envVar.Value.Run(param) //nolint:wrapcheck
+}
+
+func validEnvType(typ string) bool {
+	for _, t := range EnvTypes() {
+		if typ == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package buildspec_test
+
+import (
+	"testing"
+
+	"github.com/suzuki-shunsuke/lambuild/pkg/buildspec"
+	"gopkg.in/yaml.v2"
+)
+
+func TestEnvVar_UnmarshalYAML(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title   string
+		src     string
+		isErr   bool
+		expType string
+		exp     string
+	}{
+		{
+			title:   "string",
+			src:     `'"bar"'`,
+			expType: buildspec.EnvTypePlaintext,
+			exp:     "bar",
+		},
+		{
+			title: `map`,
+			src: `
+value: '"/lambuild/foo"'
+type: PARAMETER_STORE`,
+			expType: buildspec.EnvTypeParameterStore,
+			exp:     "/lambuild/foo",
+		},
+		{
+			title: `default type`,
+			src: `
+value: '"bar"'`,
+			expType: buildspec.EnvTypePlaintext,
+			exp:     "bar",
+		},
+		{
+			title: `invalid type`,
+			src: `
+value: '"bar"'
+type: foo`,
+			isErr: true,
+		},
+		{
+			title: `value is required`,
+			src: `
+type: SECRETS_MANAGER`,
+			isErr: true,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			envVar := buildspec.EnvVar{}
+			err := yaml.Unmarshal([]byte(d.src), &envVar)
+			if d.isErr {
+				if err == nil {
+					t.Fatal("error should be returned")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if envVar.Type != d.expType {
+				t.Fatalf("type: got %s, wanted %s", envVar.Type, d.expType)
+			}
+			s, err := envVar.Run(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s != d.exp {
+				t.Fatalf("value: got %s, wanted %s", s, d.exp)
+			}
+		})
+	}
+}
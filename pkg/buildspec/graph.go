@@ -2,16 +2,19 @@ package buildspec
 
 import (
 	"github.com/suzuki-shunsuke/lambuild/pkg/expr"
+	"github.com/suzuki-shunsuke/lambuild/pkg/pathfilter"
 )
 
 type GraphElement struct {
 	Identifier    string
-	Buildspec     string    `yaml:",omitempty"`
-	DependOn      []string  `yaml:"depend-on,omitempty"`
-	Env           GraphEnv  `yaml:",omitempty"`
-	DebugSession  bool      `yaml:"debug-session,omitempty"`
-	IgnoreFailure bool      `yaml:"ignore-failure,omitempty"`
-	If            expr.Bool `yaml:",omitempty"`
+	Buildspec     string              `yaml:",omitempty"`
+	DependOn      []string            `yaml:"depend-on,omitempty"`
+	Env           GraphEnv            `yaml:",omitempty"`
+	DebugSession  bool                `yaml:"debug-session,omitempty"`
+	IgnoreFailure bool                `yaml:"ignore-failure,omitempty"`
+	If            expr.Bool           `yaml:",omitempty"`
+	Paths         pathfilter.Patterns `yaml:",omitempty"`
+	PathsIgnore   pathfilter.Patterns `yaml:"paths-ignore,omitempty"`
 }
 
 type GraphEnv struct {
@@ -2,15 +2,18 @@ package buildspec
 
 import (
 	"github.com/suzuki-shunsuke/lambuild/pkg/expr"
+	"github.com/suzuki-shunsuke/lambuild/pkg/pathfilter"
 )
 
 type ListElement struct {
 	Identifier    string
-	Buildspec     string    `yaml:",omitempty"`
-	Env           ListEnv   `yaml:",omitempty"`
-	DebugSession  bool      `yaml:"debug-session,omitempty"`
-	IgnoreFailure bool      `yaml:"ignore-failure,omitempty"`
-	If            expr.Bool `yaml:",omitempty"`
+	Buildspec     string              `yaml:",omitempty"`
+	Env           ListEnv             `yaml:",omitempty"`
+	DebugSession  bool                `yaml:"debug-session,omitempty"`
+	IgnoreFailure bool                `yaml:"ignore-failure,omitempty"`
+	If            expr.Bool           `yaml:",omitempty"`
+	Paths         pathfilter.Patterns `yaml:",omitempty"`
+	PathsIgnore   pathfilter.Patterns `yaml:"paths-ignore,omitempty"`
 }
 
 type ListEnv struct {
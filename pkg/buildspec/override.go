@@ -0,0 +1,71 @@
+package buildspec
+
+// Cache overrides the project's cache.
+type Cache struct {
+	Type     string
+	Location string
+	Modes    []string
+}
+
+// Artifacts overrides the project's artifacts.
+type Artifacts struct {
+	Type                 string
+	Location             string
+	Path                 string
+	Name                 string
+	NamespaceType        string `yaml:"namespace-type"`
+	Packaging            string
+	EncryptionDisabled   *bool  `yaml:"encryption-disabled"`
+	OverrideArtifactName *bool  `yaml:"override-artifact-name"`
+	ArtifactIdentifier   string `yaml:"artifact-identifier"`
+}
+
+// LogsConfig overrides the project's logs configuration.
+type LogsConfig struct {
+	CloudWatchLogs *CloudWatchLogs `yaml:"cloudwatch-logs"`
+	S3Logs         *S3Logs         `yaml:"s3-logs"`
+}
+
+type CloudWatchLogs struct {
+	Status     string
+	GroupName  string `yaml:"group-name"`
+	StreamName string `yaml:"stream-name"`
+}
+
+type S3Logs struct {
+	Status             string
+	Location           string
+	EncryptionDisabled *bool `yaml:"encryption-disabled"`
+}
+
+// Source overrides the project's secondary source.
+type Source struct {
+	Type              string
+	Location          string
+	SourceIdentifier  string `yaml:"source-identifier"`
+	SourceVersion     string `yaml:"source-version"`
+	GitCloneDepth     *int64 `yaml:"git-clone-depth"`
+	Buildspec         string
+	ReportBuildStatus *bool `yaml:"report-build-status"`
+	InsecureSSL       *bool `yaml:"insecure-ssl"`
+}
+
+// SourceAuth overrides the authorization of the project's source.
+type SourceAuth struct {
+	Type     string
+	Resource string
+}
+
+// BuildBatchConfig overrides the project's batch configuration.
+// It is danger to allow to override the batch's Service Role,
+// so lambuild doesn't support to override it.
+type BuildBatchConfig struct {
+	CombineArtifacts *bool              `yaml:"combine-artifacts"`
+	TimeoutInMinutes *int64             `yaml:"timeout-in-minutes"`
+	Restrictions     *BatchRestrictions `yaml:"restrictions"`
+}
+
+type BatchRestrictions struct {
+	ComputeTypesAllowed  []string `yaml:"compute-types-allowed"`
+	MaximumBuildsAllowed *int64   `yaml:"maximum-builds-allowed"`
+}
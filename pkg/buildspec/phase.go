@@ -1,5 +1,8 @@
 package buildspec
 
+// PhaseCodeBuildKeys are keys of CodeBuild's buildspec phase which lambuild passes to CodeBuild as they are.
+var PhaseCodeBuildKeys = []string{"run-as", "on-failure"} //nolint:gochecknoglobals
+
 type Phase struct {
 	Commands Commands               `yaml:",omitempty"`
 	Finally  Commands               `yaml:",omitempty"`
@@ -2,20 +2,46 @@ package config
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 	"github.com/suzuki-shunsuke/lambuild/pkg/expr"
+	"github.com/suzuki-shunsuke/lambuild/pkg/pathfilter"
 	"github.com/suzuki-shunsuke/lambuild/pkg/template"
 )
 
 type Config struct {
-	Region                    string
-	Repositories              []Repository
-	LogLevel                  LogLevel          `yaml:"log-level"`
-	BuildStatusContext        template.Template `yaml:"build-status-context"`
-	ErrorNotificationTemplate template.Template `yaml:"error-notification-template"`
-	SSMParameter              SSMParameter      `yaml:"ssm-parameter"`
-	SecretsManager            SecretsManager    `yaml:"secrets-manager"`
+	Region       string
+	Repositories []Repository
+	// Organizations are default configurations of repositories in organizations.
+	Organizations             []Organization
+	LogLevel                  LogLevel                 `yaml:"log-level"`
+	BuildStatusContext        template.TrustedTemplate `yaml:"build-status-context"`
+	ErrorNotificationTemplate template.TrustedTemplate `yaml:"error-notification-template"`
+	SSMParameter              SSMParameter             `yaml:"ssm-parameter"`
+	SecretsManager            SecretsManager           `yaml:"secrets-manager"`
+	// AllowAllTemplateFunctions allows templates in the configuration to use all sprig functions including env and expandenv.
+	// Templates in repositories can't use them regardless of this setting.
+	AllowAllTemplateFunctions bool `yaml:"allow-all-template-functions"`
+}
+
+// CompileTemplates compiles templates in the configuration.
+// CompileTemplates must be called after the configuration is read.
+func (cfg *Config) CompileTemplates() error {
+	if err := cfg.BuildStatusContext.Compile(cfg.AllowAllTemplateFunctions); err != nil {
+		return fmt.Errorf("compile build-status-context: %w", err)
+	}
+	if err := cfg.ErrorNotificationTemplate.Compile(cfg.AllowAllTemplateFunctions); err != nil {
+		return fmt.Errorf("compile error-notification-template: %w", err)
+	}
+	for i := range cfg.Repositories {
+		repo := &cfg.Repositories[i]
+		if err := repo.Authorization.DeniedComment.Compile(cfg.AllowAllTemplateFunctions); err != nil {
+			return fmt.Errorf("compile authorization.denied-comment (repo: %s): %w", repo.Name, err)
+		}
+	}
+	return nil
 }
 
 type LogLevel struct {
@@ -44,14 +70,178 @@ type SSMParameter struct {
 }
 
 type ParameterName struct {
-	GitHubToken   string `yaml:"github-token"`
-	WebhookSecret string `yaml:"webhook-secret"`
+	GitHubToken         string `yaml:"github-token"`
+	WebhookSecret       string `yaml:"webhook-secret"`
+	GitHubAppID         string `yaml:"github-app-id"`
+	GitHubAppPrivateKey string `yaml:"github-app-private-key"`
 }
 
 type Repository struct {
-	Name      string
-	Hooks     []Hook
-	CodeBuild CodeBuild `yaml:"codebuild"`
+	// Name is either a repository full name or a glob pattern like "myorg/*".
+	Name string
+	// NameRegexp is a regular expression of repository full names. NameRegexp is used if Name is empty.
+	NameRegexp   Regexp `yaml:"name-regexp"`
+	Hooks        []Hook
+	CodeBuild    CodeBuild    `yaml:"codebuild"`
+	IssueComment IssueComment `yaml:"issue-comment"`
+	// AutoCancel stops in progress builds of the same pull request or branch when new builds are started.
+	AutoCancel      bool            `yaml:"auto-cancel"`
+	ForkPullRequest ForkPullRequest `yaml:"fork-pull-request"`
+	Authorization   Authorization   `yaml:"authorization"`
+	// HookMode is either "first" or "all". The default is "first".
+	HookMode    string      `yaml:"hook-mode"`
+	BuildPolicy BuildPolicy `yaml:"build-policy"`
+	// AllowedSecretPrefixes restricts parameters and secrets which lambuild.yaml can read.
+	AllowedSecretPrefixes SecretPrefixes `yaml:"allowed-secret-prefixes"`
+}
+
+// SecretPrefixes are allowed name prefixes of PARAMETER_STORE and SECRETS_MANAGER environment variables.
+// If the list is empty, the type of environment variables isn't allowed.
+type SecretPrefixes struct {
+	ParameterStore []string `yaml:"parameter-store"`
+	SecretsManager []string `yaml:"secrets-manager"`
+}
+
+// Enabled returns true if any prefix is configured.
+func (prefixes *SecretPrefixes) Enabled() bool {
+	return len(prefixes.ParameterStore) != 0 || len(prefixes.SecretsManager) != 0
+}
+
+// BuildPolicy restricts build environments which lambuild.yaml can configure.
+// If an allowlist is empty, any value is allowed.
+type BuildPolicy struct {
+	// Images are glob patterns of github.com/bmatcuk/doublestar.
+	Images           []string
+	ComputeTypes     []string `yaml:"compute-types"`
+	EnvironmentTypes []string `yaml:"environment-types"`
+	// AllowPrivilegedMode is whether privileged-mode can be enabled. The default is true.
+	AllowPrivilegedMode *bool `yaml:"allow-privileged-mode"`
+	// MaximumBuildsAllowed is the maximum of build-batch-config.restrictions.maximum-builds-allowed. If this is 0, any value is allowed.
+	MaximumBuildsAllowed int64 `yaml:"maximum-builds-allowed"`
+}
+
+// Merge returns a policy whose fields are overridden by p's non empty fields.
+func (policy BuildPolicy) Merge(p BuildPolicy) BuildPolicy {
+	if len(p.Images) != 0 {
+		policy.Images = p.Images
+	}
+	if len(p.ComputeTypes) != 0 {
+		policy.ComputeTypes = p.ComputeTypes
+	}
+	if len(p.EnvironmentTypes) != 0 {
+		policy.EnvironmentTypes = p.EnvironmentTypes
+	}
+	if p.AllowPrivilegedMode != nil {
+		policy.AllowPrivilegedMode = p.AllowPrivilegedMode
+	}
+	if p.MaximumBuildsAllowed != 0 {
+		policy.MaximumBuildsAllowed = p.MaximumBuildsAllowed
+	}
+	return policy
+}
+
+// PrivilegedModeAllowed returns true if privileged-mode can be enabled.
+func (policy BuildPolicy) PrivilegedModeAllowed() bool {
+	return policy.AllowPrivilegedMode == nil || *policy.AllowPrivilegedMode
+}
+
+const (
+	// HookModeFirst runs only the first hook which the event matches.
+	HookModeFirst = "first"
+	// HookModeAll runs all hooks which the event matches.
+	HookModeAll = "all"
+)
+
+const (
+	// AuthorizationUserSender authorizes the sender of the event.
+	AuthorizationUserSender = "sender"
+	// AuthorizationUserPRAuthor authorizes the author of the pull request.
+	// If the event isn't associated with a pull request, the sender is authorized.
+	AuthorizationUserPRAuthor = "pr-author"
+)
+
+// Authorization restricts users who can trigger builds.
+// The user is authorized if the user satisfies any of Permission, Teams, and Users.
+// If none of them is configured, anyone can trigger builds.
+type Authorization struct {
+	// User is either "sender" or "pr-author". The default is "sender".
+	User       string
+	Permission Permission
+	// Teams are GitHub teams whose members are authorized. The format is "<organization>/<team slug>".
+	Teams []string
+	// Users are GitHub users who are authorized.
+	Users []string
+	// DeniedComment is the template of the pull request comment which is sent when the user isn't authorized.
+	DeniedComment template.TrustedTemplate `yaml:"denied-comment"`
+}
+
+// SplitTeam splits "<organization>/<team slug>" into the organization and the team slug.
+func SplitTeam(team string) (string, string, bool) {
+	a := strings.Split(team, "/")
+	if len(a) != 2 || a[0] == "" || a[1] == "" { //nolint:gomnd
+		return "", "", false
+	}
+	return a[0], a[1], true
+}
+
+// Enabled returns true if any condition is configured.
+func (authz *Authorization) Enabled() bool {
+	return !authz.Permission.Empty() || len(authz.Teams) != 0 || len(authz.Users) != 0
+}
+
+// ForkPullRequest is the policy of pull requests from forked repositories.
+type ForkPullRequest struct {
+	Policy ForkPolicy
+	// Label is the label which is required if the policy is require-label.
+	Label string
+	// Permission is the minimum permission level of the reviewer if the policy is require-approval.
+	Permission Permission
+}
+
+// Organization is the default configuration of repositories in the organization.
+// If a repository's configuration doesn't have CodeBuild settings or hooks, the organization's ones are used.
+type Organization struct {
+	Name        string
+	Hooks       []Hook
+	CodeBuild   CodeBuild   `yaml:"codebuild"`
+	BuildPolicy BuildPolicy `yaml:"build-policy"`
+}
+
+// Regexp is a regular expression.
+type Regexp struct {
+	regexp *regexp.Regexp
+}
+
+func (re *Regexp) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	r, err := regexp.Compile(s)
+	if err != nil {
+		return fmt.Errorf("compile a regular expression (%s): %w", s, err)
+	}
+	re.regexp = r
+	return nil
+}
+
+func (re *Regexp) Empty() bool {
+	return re.regexp == nil
+}
+
+// MatchString returns true if s matches the regular expression.
+// If the regular expression is empty, false is returned.
+func (re *Regexp) MatchString(s string) bool {
+	if re.regexp == nil {
+		return false
+	}
+	return re.regexp.MatchString(s)
+}
+
+// IssueComment is the configuration of commands in pull request comments like `/lambuild run`.
+type IssueComment struct {
+	Disabled   bool
+	Permission Permission
 }
 
 type CodeBuild struct {
@@ -65,9 +255,158 @@ type Hook struct {
 	ServiceRole   string `yaml:"service-role"`
 	ProjectName   string `yaml:"project-name"`
 	AssumeRoleARN string `yaml:"assume-role-arn"`
+	// AutoCancel overrides the repository's auto-cancel.
+	AutoCancel  *bool               `yaml:"auto-cancel"`
+	Paths       pathfilter.Patterns `yaml:"paths"`
+	PathsIgnore pathfilter.Patterns `yaml:"paths-ignore"`
+	// AllowedOverrides are allowlists of security-sensitive overrides in lambuild.yaml.
+	AllowedOverrides AllowedOverrides `yaml:"allowed-overrides"`
+	// BuildPolicy overrides the repository's build policy.
+	BuildPolicy BuildPolicy `yaml:"build-policy"`
+}
+
+// AllowedOverrides are allowlists of security-sensitive overrides in lambuild.yaml.
+// Elements are glob patterns of github.com/bmatcuk/doublestar.
+// If the allowlist is empty, the override isn't allowed.
+type AllowedOverrides struct {
+	// EncryptionKeys are KMS key ARNs or aliases of encryption-key
+	EncryptionKeys []string `yaml:"encryption-keys"`
+	// SourceAuthResources are resources of source-auth
+	SourceAuthResources []string `yaml:"source-auth-resources"`
+	// SecondarySourceLocations are locations of secondary-sources
+	SecondarySourceLocations []string `yaml:"secondary-source-locations"`
+	// ArtifactLocations are S3 bucket names of artifacts
+	ArtifactLocations []string `yaml:"artifact-locations"`
+	// CacheLocations are S3 locations of cache
+	CacheLocations []string `yaml:"cache-locations"`
+	// LogLocations are CloudWatch Logs group names and S3 locations of logs-config
+	LogLocations []string `yaml:"log-locations"`
+	// EncryptionDisabled allows encryption-disabled of artifacts and logs-config.s3-logs
+	EncryptionDisabled bool `yaml:"encryption-disabled"`
+	// BuildBatchConfig allows build-batch-config, which can change restrictions of batch builds
+	BuildBatchConfig bool `yaml:"build-batch-config"`
 }
 
 type SecretsManager struct {
 	SecretID  string `yaml:"secret-id"`
 	VersionID string `yaml:"version-id"`
 }
+
+// permissionLevels is the order of GitHub repository permission levels.
+// GitHub API returns one of "admin", "write", "read", and "none" as the permission level.
+var permissionLevels = map[string]int{ //nolint:gochecknoglobals
+	"none":  0,
+	"read":  1,
+	"write": 2, //nolint:gomnd
+	"admin": 3, //nolint:gomnd
+}
+
+// defaultPermission is the permission level which is required if the permission isn't configured.
+const defaultPermission = "write"
+
+// Permission is the minimum GitHub repository permission level which is required.
+type Permission struct {
+	name string
+}
+
+// PermissionLevels returns permission level names in ascending order.
+func PermissionLevels() []string {
+	return []string{"none", "read", "write", "admin"}
+}
+
+func NewPermission(name string) (Permission, error) {
+	if _, ok := permissionLevels[name]; !ok {
+		return Permission{}, fmt.Errorf("permission is invalid (%s). permission must be one of none, read, write, and admin", name)
+	}
+	return Permission{name: name}, nil
+}
+
+func (permission *Permission) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	p, err := NewPermission(s)
+	if err != nil {
+		return err
+	}
+	*permission = p
+	return nil
+}
+
+// Empty returns true if the permission isn't configured.
+func (permission *Permission) Empty() bool {
+	return permission.name == ""
+}
+
+// Get returns the permission level name.
+// If the permission isn't configured, "write" is returned.
+func (permission *Permission) Get() string {
+	if permission.name == "" {
+		return defaultPermission
+	}
+	return permission.name
+}
+
+// Satisfied returns true if the given permission level is equal to or higher than the required permission level.
+func (permission *Permission) Satisfied(level string) bool {
+	lvl, ok := permissionLevels[level]
+	if !ok {
+		return false
+	}
+	return lvl >= permissionLevels[permission.Get()]
+}
+
+const (
+	// ForkPolicyDeny ignores pull requests from forks.
+	ForkPolicyDeny = "deny"
+	// ForkPolicyAllow treats pull requests from forks same as other pull requests.
+	ForkPolicyAllow = "allow"
+	// ForkPolicyRequireLabel runs builds only if the pull request has the label.
+	ForkPolicyRequireLabel = "require-label"
+	// ForkPolicyRequireApproval runs builds only if the head commit is approved by a reviewer who has the permission.
+	ForkPolicyRequireApproval = "require-approval"
+	// ForkPolicyUseBaseConfig reads configuration files from the base branch instead of the head branch.
+	ForkPolicyUseBaseConfig = "use-base-config"
+)
+
+// ForkPolicy is the policy of pull requests from forked repositories.
+type ForkPolicy struct {
+	name string
+}
+
+// ForkPolicies returns policy names.
+func ForkPolicies() []string {
+	return []string{ForkPolicyDeny, ForkPolicyAllow, ForkPolicyRequireLabel, ForkPolicyRequireApproval, ForkPolicyUseBaseConfig}
+}
+
+func NewForkPolicy(name string) (ForkPolicy, error) {
+	for _, policy := range ForkPolicies() {
+		if name == policy {
+			return ForkPolicy{name: name}, nil
+		}
+	}
+	return ForkPolicy{}, fmt.Errorf("fork pull request policy is invalid (%s). policy must be one of deny, allow, require-label, require-approval, and use-base-config", name)
+}
+
+func (policy *ForkPolicy) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	p, err := NewForkPolicy(s)
+	if err != nil {
+		return err
+	}
+	*policy = p
+	return nil
+}
+
+// Get returns the policy name.
+// If the policy isn't configured, "deny" is returned.
+func (policy *ForkPolicy) Get() string {
+	if policy.name == "" {
+		return ForkPolicyDeny
+	}
+	return policy.name
+}
@@ -81,3 +81,73 @@ ssm-parameter:
 		})
 	}
 }
+
+func TestPermission_Satisfied(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title      string
+		permission string
+		level      string
+		exp        bool
+	}{
+		{
+			title: "default",
+			level: "write",
+			exp:   true,
+		},
+		{
+			title: "default read",
+			level: "read",
+			exp:   false,
+		},
+		{
+			title:      "admin is higher than read",
+			permission: "read",
+			level:      "admin",
+			exp:        true,
+		},
+		{
+			title:      "none",
+			permission: "none",
+			level:      "none",
+			exp:        true,
+		},
+		{
+			title:      "unknown level",
+			permission: "none",
+			level:      "foo",
+			exp:        false,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			permission := config.Permission{}
+			if d.permission != "" {
+				p, err := config.NewPermission(d.permission)
+				if err != nil {
+					t.Fatal(err)
+				}
+				permission = p
+			}
+			if f := permission.Satisfied(d.level); f != d.exp {
+				t.Fatalf("got %v, wanted %v", f, d.exp)
+			}
+		})
+	}
+}
+
+func TestPermission_UnmarshalYAML(t *testing.T) {
+	t.Parallel()
+	permission := config.Permission{}
+	if err := yaml.Unmarshal([]byte("admin"), &permission); err != nil {
+		t.Fatal(err)
+	}
+	if permission.Get() != "admin" {
+		t.Fatalf(`got %s, wanted "admin"`, permission.Get())
+	}
+	if err := yaml.Unmarshal([]byte("maintainer"), &permission); err == nil {
+		t.Fatal("invalid permission should be rejected")
+	}
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v37/github"
+	"github.com/suzuki-shunsuke/lambuild/pkg/pathfilter"
+)
+
+// zeroSHA is the "before" commit SHA of the push event which creates a branch or a tag.
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+// maxCompareFiles is the maximum number of files which GitHub API "Compare two commits" returns.
+const maxCompareFiles = 300
+
+// extractPushFileNames returns paths of files which are added, removed, or modified by commits of the push event.
+func extractPushFileNames(event *github.PushEvent) []string {
+	fileNames := map[string]struct{}{}
+	for _, commit := range event.Commits {
+		for _, files := range [][]string{commit.Added, commit.Removed, commit.Modified} {
+			for _, file := range files {
+				fileNames[file] = struct{}{}
+			}
+		}
+	}
+	arr := make([]string, 0, len(fileNames))
+	for k := range fileNames {
+		arr = append(arr, k)
+	}
+	return arr
+}
+
+// mergeFileNames returns the union of file paths.
+func mergeFileNames(a, b []string) []string {
+	fileNames := make(map[string]struct{}, len(a)+len(b))
+	arr := make([]string, 0, len(a)+len(b))
+	for _, files := range [][]string{a, b} {
+		for _, file := range files {
+			if _, ok := fileNames[file]; ok {
+				continue
+			}
+			fileNames[file] = struct{}{}
+			arr = append(arr, file)
+		}
+	}
+	return arr
+}
+
+// comparePushFiles returns files changed by the push event with GitHub API "Compare two commits".
+// If the push creates a branch or a tag, the commit is compared with the default branch.
+func comparePushFiles(ctx context.Context, client GitHub, owner, repo string, event *github.PushEvent) ([]*github.CommitFile, error) {
+	if event.GetDeleted() {
+		return []*github.CommitFile{}, nil
+	}
+	base := event.GetBefore()
+	if base == "" || base == zeroSHA {
+		base = event.GetRepo().GetDefaultBranch()
+		if base == "" {
+			return []*github.CommitFile{}, nil
+		}
+	}
+	comparison, err := client.CompareCommits(ctx, owner, repo, base, event.GetAfter())
+	if err != nil {
+		return nil, fmt.Errorf("compare commits (%s...%s): %w", base, event.GetAfter(), err)
+	}
+	if comparison.Files == nil {
+		return []*github.CommitFile{}, nil
+	}
+	return comparison.Files, nil
+}
+
+func (data *Data) GetPushFiles() ([]*github.CommitFile, error) {
+	files, err := data.pushFiles(data.Context())
+	return files, data.exprFuncError("getPushFiles", err)
+}
+
+// pushFiles returns files changed by the push event.
+// If the event isn't a push event, an empty list is returned.
+func (data *Data) pushFiles(ctx context.Context) ([]*github.CommitFile, error) {
+	return data.Push.Files.Get(func() ([]*github.CommitFile, error) { //nolint:wrapcheck
+		event, ok := data.Event.Payload.(*github.PushEvent)
+		if !ok {
+			return []*github.CommitFile{}, nil
+		}
+		return comparePushFiles(ctx, data.GitHub, data.Repository.Owner, data.Repository.Name, event)
+	})
+}
+
+// pushFileNames returns paths of files changed by the push event.
+// The second returned value is false if GitHub API doesn't return all changed files.
+func (data *Data) pushFileNames(ctx context.Context) ([]string, bool, error) {
+	files, err := data.pushFiles(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	complete := len(files) < maxCompareFiles
+	val, err := data.Push.ChangedFileNames.Get(func() ([]string, error) {
+		val := extractPRFileNames(files)
+		if !complete {
+			// GitHub API returns up to 300 files, so files of commits in the payload are added
+			if event, ok := data.Event.Payload.(*github.PushEvent); ok {
+				val = mergeFileNames(val, extractPushFileNames(event))
+			}
+		}
+		return val, nil
+	})
+	return val, complete, err //nolint:wrapcheck
+}
+
+func (data *Data) GetChangedFileNames() ([]string, error) {
+	files, _, err := data.changedFileNames(data.Context())
+	return files, data.exprFuncError("getChangedFileNames", err)
+}
+
+// changedFileNames returns paths of files which are changed by the event.
+// For push events, files between "before" and "after" commits are returned.
+// For other events, files of the associated pull request are returned.
+// The second returned value is false if GitHub API doesn't return all changed files.
+func (data *Data) changedFileNames(ctx context.Context) ([]string, bool, error) {
+	if _, ok := data.Event.Payload.(*github.PushEvent); ok {
+		return data.pushFileNames(ctx)
+	}
+	files, err := data.prFileNames(ctx)
+	return files, true, err
+}
+
+// MatchPaths returns true if files changed by the event match paths and paths-ignore.
+// If both paths and paths-ignore are empty, changed files aren't got and MatchPaths returns true.
+// If GitHub API doesn't return all changed files, MatchPaths returns true so that builds aren't skipped wrongly.
+func (data *Data) MatchPaths(paths, pathsIgnore pathfilter.Patterns) (bool, error) {
+	if pathfilter.Empty(paths, pathsIgnore) {
+		return true, nil
+	}
+	files, complete, err := data.changedFileNames(data.Context())
+	if err != nil {
+		return false, fmt.Errorf("get changed files: %w", err)
+	}
+	if !complete {
+		return true, nil
+	}
+	return pathfilter.Match(paths, pathsIgnore, files), nil
+}
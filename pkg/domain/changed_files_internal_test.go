@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-github/v37/github"
+	"github.com/suzuki-shunsuke/lambuild/pkg/pathfilter"
+)
+
+type compareClient struct {
+	GitHub
+	base  string
+	files []*github.CommitFile
+}
+
+func (client *compareClient) CompareCommits(ctx context.Context, owner, repo, base, head string) (*github.CommitsComparison, error) {
+	client.base = base
+	return &github.CommitsComparison{
+		Files: client.files,
+	}, nil
+}
+
+func Test_comparePushFiles(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title string
+		event *github.PushEvent
+		base  string
+		exp   int
+	}{
+		{
+			title: "normal",
+			event: &github.PushEvent{
+				Before: github.String("b15a8a4e1d14d1a8ed2e4a8e1bd7a8a8b8a8a8a8"),
+				After:  github.String("3ed5a8ad1d4bb4b1e4a1ab2d68aa5d1cd1d2a5c6"),
+			},
+			base: "b15a8a4e1d14d1a8ed2e4a8e1bd7a8a8b8a8a8a8",
+			exp:  1,
+		},
+		{
+			title: "new branch",
+			event: &github.PushEvent{
+				Before: github.String(zeroSHA),
+				After:  github.String("3ed5a8ad1d4bb4b1e4a1ab2d68aa5d1cd1d2a5c6"),
+				Repo: &github.PushEventRepository{
+					DefaultBranch: github.String("main"),
+				},
+			},
+			base: "main",
+			exp:  1,
+		},
+		{
+			title: "deleted",
+			event: &github.PushEvent{
+				Before:  github.String("b15a8a4e1d14d1a8ed2e4a8e1bd7a8a8b8a8a8a8"),
+				After:   github.String(zeroSHA),
+				Deleted: github.Bool(true),
+			},
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			client := &compareClient{
+				files: []*github.CommitFile{
+					{Filename: github.String("main.go")},
+				},
+			}
+			files, err := comparePushFiles(context.Background(), client, "suzuki-shunsuke", "test-lambuild", d.event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if client.base != d.base {
+				t.Fatalf("base: wanted %s, got %s", d.base, client.base)
+			}
+			if len(files) != d.exp {
+				t.Fatalf("the number of files: wanted %d, got %d", d.exp, len(files))
+			}
+		})
+	}
+}
+
+func TestData_MatchPaths(t *testing.T) {
+	t.Parallel()
+	files := make([]*github.CommitFile, maxCompareFiles)
+	for i := range files {
+		files[i] = &github.CommitFile{Filename: github.String(fmt.Sprintf("docs/%d.md", i))}
+	}
+	data := NewData()
+	data.Event.Payload = &github.PushEvent{
+		Before: github.String("b15a8a4e1d14d1a8ed2e4a8e1bd7a8a8b8a8a8a8"),
+		After:  github.String("3ed5a8ad1d4bb4b1e4a1ab2d68aa5d1cd1d2a5c6"),
+		Commits: []*github.HeadCommit{
+			{
+				Modified: []string{"main.go"},
+			},
+		},
+	}
+	data.GitHub = &compareClient{
+		files: files,
+	}
+	names, err := data.GetChangedFileNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != maxCompareFiles+1 {
+		t.Fatalf("files of commits in the payload must be added: %d", len(names))
+	}
+	f, err := data.MatchPaths(pathfilter.Patterns{"foo/**"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f {
+		t.Fatal("MatchPaths must return true if GitHub API doesn't return all changed files")
+	}
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/go-github/v37/github"
+)
+
+// ExprFuncError is an error of a function which is called in expressions and templates.
+// StatusCode is the HTTP status code of GitHub API.
+// If the error isn't caused by GitHub API's error response, StatusCode is zero.
+// Err may be context.Canceled or context.DeadlineExceeded if the request is canceled or times out.
+type ExprFuncError struct {
+	Function   string
+	Repository string
+	StatusCode int
+	Err        error
+}
+
+func (e *ExprFuncError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s failed (repository: %s, status: %d): %v", e.Function, e.Repository, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("%s failed (repository: %s): %v", e.Function, e.Repository, e.Err)
+}
+
+func (e *ExprFuncError) Unwrap() error {
+	return e.Err
+}
+
+// exprFuncError returns an ExprFuncError. If err is nil, nil is returned.
+func (data *Data) exprFuncError(function string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ExprFuncError{
+		Function:   function,
+		Repository: data.Repository.FullName,
+		StatusCode: statusCode(err),
+		Err:        err,
+	}
+}
+
+// statusCode returns the HTTP status code of GitHub API's error response.
+// If err isn't GitHub API's error response, zero is returned.
+func statusCode(err error) int {
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil {
+		return errResp.Response.StatusCode
+	}
+	var rateLimitErr *github.RateLimitError
+	if errors.As(err, &rateLimitErr) && rateLimitErr.Response != nil {
+		return rateLimitErr.Response.StatusCode
+	}
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) && abuseErr.Response != nil {
+		return abuseErr.Response.StatusCode
+	}
+	return 0
+}
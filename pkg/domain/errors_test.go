@@ -0,0 +1,84 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v37/github"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+	"github.com/suzuki-shunsuke/lambuild/pkg/expr"
+)
+
+type prClient struct {
+	domain.GitHub
+	err error
+}
+
+func (client *prClient) GetPR(ctx context.Context, owner, repo string, number int) (*github.PullRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	return nil, fmt.Errorf("get a pull request by GitHub API: %w", client.err)
+}
+
+func TestExprFuncError(t *testing.T) {
+	t.Parallel()
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	data := []struct {
+		title         string
+		ctx           context.Context
+		expStatusCode int
+		expErr        error
+	}{
+		{
+			title:         "status code",
+			ctx:           context.Background(),
+			expStatusCode: http.StatusNotFound,
+		},
+		{
+			title:  "canceled",
+			ctx:    canceledCtx,
+			expErr: context.Canceled,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			dt := domain.NewData()
+			dt.SetContext(d.ctx)
+			dt.Repository.FullName = "suzuki-shunsuke/test-lambuild"
+			dt.PullRequest.Number.Set(5)
+			dt.GitHub = &prClient{
+				err: &github.ErrorResponse{
+					Response: &http.Response{StatusCode: http.StatusNotFound},
+				},
+			}
+			prog := expr.NewBoolForTest(t, `getPR().GetNumber() == 5`)
+			_, err := prog.Run(dt.Convert())
+			if err == nil {
+				t.Fatal("error should be returned")
+			}
+			var funcErr *domain.ExprFuncError
+			if !errors.As(err, &funcErr) {
+				t.Fatalf("error should be ExprFuncError: %v", err)
+			}
+			if funcErr.Function != "getPR" {
+				t.Fatalf("function: got %s, wanted getPR", funcErr.Function)
+			}
+			if funcErr.Repository != "suzuki-shunsuke/test-lambuild" {
+				t.Fatalf("repository: got %s, wanted suzuki-shunsuke/test-lambuild", funcErr.Repository)
+			}
+			if funcErr.StatusCode != d.expStatusCode {
+				t.Fatalf("status code: got %d, wanted %d", funcErr.StatusCode, d.expStatusCode)
+			}
+			if d.expErr != nil && !errors.Is(err, d.expErr) {
+				t.Fatalf("error should wrap %v: %v", d.expErr, err)
+			}
+		})
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 
 	"github.com/google/go-github/v37/github"
-	"github.com/suzuki-shunsuke/lambuild/pkg/mutex"
+	"github.com/suzuki-shunsuke/lambuild/pkg/lazy"
 )
 
 type Event struct {
@@ -21,20 +21,34 @@ type Headers struct {
 }
 
 type PullRequest struct {
-	ChangedFileNames mutex.StringList
-	LabelNames       mutex.StringList
-	PullRequest      mutex.PR
-	Files            mutex.CommitFiles
-	Number           mutex.Int
+	ChangedFileNames lazy.Value[[]string]
+	LabelNames       lazy.Value[[]string]
+	PullRequest      lazy.Value[*github.PullRequest]
+	Files            lazy.Value[[]*github.CommitFile]
+	// Number is 0 if the event isn't associated with any pull request.
+	Number lazy.Value[int]
 }
 
 func NewPullRequest() PullRequest {
 	return PullRequest{
-		ChangedFileNames: mutex.NewStringList(),
-		LabelNames:       mutex.NewStringList(),
-		PullRequest:      mutex.NewPR(),
-		Files:            mutex.NewCommitFiles(),
-		Number:           mutex.NewInt(),
+		ChangedFileNames: lazy.New[[]string](),
+		LabelNames:       lazy.New[[]string](),
+		PullRequest:      lazy.New[*github.PullRequest](),
+		Files:            lazy.New[[]*github.CommitFile](),
+		Number:           lazy.New[int](),
+	}
+}
+
+// Push is data of the push event.
+type Push struct {
+	Files            lazy.Value[[]*github.CommitFile]
+	ChangedFileNames lazy.Value[[]string]
+}
+
+func NewPush() Push {
+	return Push{
+		Files:            lazy.New[[]*github.CommitFile](),
+		ChangedFileNames: lazy.New[[]string](),
 	}
 }
 
@@ -49,13 +63,35 @@ type Repository struct {
 type Data struct {
 	Event             Event
 	PullRequest       PullRequest
+	Push              Push
 	Repository        Repository
-	HeadCommitMessage mutex.String
+	HeadCommitMessage lazy.Value[string]
 	SHA               string
 	Ref               string
 	GitHub            GitHub
-	Commit            mutex.Commit
+	Commit            lazy.Value[*github.Commit]
 	AWS               AWSData
+	// Tree is the tree of the repository at the event's commit.
+	Tree lazy.Value[*github.Tree]
+	// env is the environment of expressions and templates except for values which are changed per hook. It is built once per event.
+	env lazy.Value[map[string]interface{}]
+	// ctx is the context of the request. GitHub API is called with ctx in expressions and templates.
+	ctx context.Context
+}
+
+// SetContext sets the context of the request.
+// Functions in expressions and templates call GitHub API with the context, so they honor the cancellation and deadline of the request.
+func (data *Data) SetContext(ctx context.Context) {
+	data.ctx = ctx
+}
+
+// Context returns the context of the request.
+// If the context isn't set, context.Background() is returned.
+func (data *Data) Context() context.Context {
+	if data.ctx == nil {
+		return context.Background()
+	}
+	return data.ctx
 }
 
 type AWSData struct {
@@ -70,64 +106,135 @@ type GitHub interface {
 	GetPRFiles(ctx context.Context, owner, repo string, number int, opt *github.ListOptions) ([]*github.CommitFile, error)
 	GetPRsWithCommit(ctx context.Context, owner, repo string, sha string) ([]*github.PullRequest, error)
 	GetContents(ctx context.Context, owner, repo, path, ref string) (*github.RepositoryContent, []*github.RepositoryContent, error)
+	GetTree(ctx context.Context, owner, repo, sha string) (*github.Tree, error)
 	CreateCommitComment(ctx context.Context, owner, repo, sha, body string) error
 	CreatePRComment(ctx context.Context, owner, repo string, number int, body string) error
+	RemoveLabel(ctx context.Context, owner, repo string, number int, label string) error
+	GetPermissionLevel(ctx context.Context, owner, repo, user string) (string, error)
+	CompareCommits(ctx context.Context, owner, repo, base, head string) (*github.CommitsComparison, error)
+	ListReviews(ctx context.Context, owner, repo string, number int, opt *github.ListOptions) ([]*github.PullRequestReview, error)
+	IsTeamMember(ctx context.Context, org, teamSlug, user string) (bool, error)
 }
 
 func NewData() Data {
 	return Data{
-		Commit:            mutex.NewCommit(),
-		HeadCommitMessage: mutex.NewString(""),
+		Commit:            lazy.New[*github.Commit](),
+		HeadCommitMessage: lazy.New[string](),
 		PullRequest:       NewPullRequest(),
+		Push:              NewPush(),
+		Tree:              lazy.New[*github.Tree](),
+		env:               lazy.New[map[string]interface{}](),
 	}
 }
 
+// Convert returns the environment of expressions and templates.
+// Values of the event are built at the first call and cached, so Convert must be called after the event's data is set up.
+// Values which are changed per hook such as the CodeBuild project name are set at every call.
+// The returned map is a new map, so callers can add values to it.
 func (data *Data) Convert() map[string]interface{} {
-	return setExprFuncs(map[string]interface{}{
-		"event":            data.Event,
-		"repo":             data.Repository,
-		"sha":              data.SHA,
-		"ref":              data.Ref,
-		"getCommit":        data.GetCommit,
-		"getCommitMessage": data.CommitMessage,
-		"getPR":            data.GetPR,
-		"getPRNumber":      data.GetPRNumber,
-		"getPRFiles":       data.GetPRFiles,
-		"getPRFileNames":   data.GetPRFileNames,
-		"getPRLabelNames":  data.GetPRLabelNames,
-		"aws": map[string]interface{}{
-			"Region":    data.AWS.Region,
-			"AccountID": data.AWS.AccountID,
-			"Codebuild": map[string]interface{}{
-				"ProjectName": data.AWS.CodeBuildProjectName,
-			},
+	eventEnv := data.eventEnv()
+	env := make(map[string]interface{}, len(eventEnv)+1)
+	for k, v := range eventEnv {
+		env[k] = v
+	}
+	env["aws"] = map[string]interface{}{
+		"Region":    data.AWS.Region,
+		"AccountID": data.AWS.AccountID,
+		"Codebuild": map[string]interface{}{
+			"ProjectName": data.AWS.CodeBuildProjectName,
 		},
-	})
+	}
+	return env
 }
 
-func (data *Data) CommitMessage() string {
-	if msg := data.HeadCommitMessage.Get(); msg != "" {
-		return msg
+// eventEnv returns the cached environment of the event.
+func (data *Data) eventEnv() map[string]interface{} {
+	if !data.env.Valid() {
+		// data isn't created by NewData
+		return data.newEnv()
 	}
-	msg := data.GetCommit().GetMessage()
-	data.HeadCommitMessage.Set(msg)
-	return msg
+	env, _ := data.env.Get(func() (map[string]interface{}, error) {
+		return data.newEnv(), nil
+	})
+	return env
 }
 
-func (data *Data) GetPRFileNames() []string {
-	if val := data.PullRequest.ChangedFileNames.Get(); val != nil {
-		return val
-	}
-	val := extractPRFileNames(data.GetPRFiles())
-	data.PullRequest.ChangedFileNames.Set(val)
-	return val
+// ExprEnv returns an environment which is used to type-check expressions when the configuration is read.
+// Values in the environment are dummy, so the environment mustn't be used to evaluate expressions.
+func ExprEnv() map[string]interface{} {
+	env := (&Data{}).Convert()
+	env["item"] = map[string]interface{}{}
+	return env
 }
 
-func (data *Data) GetPRLabelNames() []string {
-	if val := data.PullRequest.LabelNames.Get(); val != nil {
-		return val
-	}
-	val := extractLabelNames(data.GetPR().Labels)
-	data.PullRequest.LabelNames.Set(val)
-	return val
+func (data *Data) newEnv() map[string]interface{} {
+	return setExprFuncs(map[string]interface{}{
+		"event":               data.Event,
+		"repo":                data.Repository,
+		"sha":                 data.SHA,
+		"ref":                 data.Ref,
+		"getCommit":           data.GetCommit,
+		"getCommitMessage":    data.CommitMessage,
+		"getPR":               data.GetPR,
+		"getPRNumber":         data.GetPRNumber,
+		"getPRFiles":          data.GetPRFiles,
+		"getPRFileNames":      data.GetPRFileNames,
+		"getPRLabelNames":     data.GetPRLabelNames,
+		"getPRAddedFiles":     data.GetPRAddedFiles,
+		"getPRRemovedFiles":   data.GetPRRemovedFiles,
+		"getPRModifiedFiles":  data.GetPRModifiedFiles,
+		"getPRRenamedFiles":   data.GetPRRenamedFiles,
+		"getPRFileStats":      data.GetPRFileStats,
+		"getPushFiles":        data.GetPushFiles,
+		"getChangedFileNames": data.GetChangedFileNames,
+		"anyFileMatches":      data.AnyFileMatches,
+		"allFilesMatch":       data.AllFilesMatch,
+		"changedDirs":         data.ChangedDirs,
+		"changedModules":      data.ChangedModules,
+	})
+}
+
+func (data *Data) CommitMessage() (string, error) {
+	msg, err := data.commitMessage(data.Context())
+	return msg, data.exprFuncError("getCommitMessage", err)
+}
+
+func (data *Data) commitMessage(ctx context.Context) (string, error) {
+	return data.HeadCommitMessage.Get(func() (string, error) { //nolint:wrapcheck
+		commit, err := data.commit(ctx)
+		if err != nil {
+			return "", err
+		}
+		return commit.GetMessage(), nil
+	})
+}
+
+func (data *Data) GetPRFileNames() ([]string, error) {
+	val, err := data.prFileNames(data.Context())
+	return val, data.exprFuncError("getPRFileNames", err)
+}
+
+func (data *Data) prFileNames(ctx context.Context) ([]string, error) {
+	return data.PullRequest.ChangedFileNames.Get(func() ([]string, error) { //nolint:wrapcheck
+		files, err := data.prFiles(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return extractPRFileNames(files), nil
+	})
+}
+
+func (data *Data) GetPRLabelNames() ([]string, error) {
+	val, err := data.prLabelNames(data.Context())
+	return val, data.exprFuncError("getPRLabelNames", err)
+}
+
+func (data *Data) prLabelNames(ctx context.Context) ([]string, error) {
+	return data.PullRequest.LabelNames.Get(func() ([]string, error) { //nolint:wrapcheck
+		pr, err := data.pr(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return extractLabelNames(pr.Labels), nil
+	})
 }
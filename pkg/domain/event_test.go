@@ -4,7 +4,7 @@ import (
 	"testing"
 
 	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
-	"github.com/suzuki-shunsuke/lambuild/pkg/mutex"
+	"github.com/suzuki-shunsuke/lambuild/pkg/lazy"
 )
 
 func TestData_CommitMessage(t *testing.T) {
@@ -17,7 +17,7 @@ func TestData_CommitMessage(t *testing.T) {
 		{
 			title: "normal",
 			data: domain.Data{
-				HeadCommitMessage: mutex.NewString("hello"),
+				HeadCommitMessage: lazy.NewWithValue("hello"),
 			},
 			exp: "hello",
 		},
@@ -26,10 +26,36 @@ func TestData_CommitMessage(t *testing.T) {
 		d := d
 		t.Run(d.title, func(t *testing.T) {
 			t.Parallel()
-			msg := d.data.CommitMessage()
+			msg, err := d.data.CommitMessage()
+			if err != nil {
+				t.Fatal(err)
+			}
 			if msg != d.exp {
 				t.Fatalf("got %s, wanted %s", msg, d.exp)
 			}
 		})
 	}
 }
+
+func TestData_Convert(t *testing.T) {
+	t.Parallel()
+	data := domain.NewData()
+	data.SHA = "0123456"
+	data.AWS.CodeBuildProjectName = "repo-project"
+	env := data.Convert()
+	if env["sha"] != "0123456" {
+		t.Fatalf(`got %v, wanted "0123456"`, env["sha"])
+	}
+	// values of the event are cached
+	data.SHA = "abcdefg"
+	// the project name is changed per hook
+	data.AWS.CodeBuildProjectName = "hook-project"
+	env = data.Convert()
+	if env["sha"] != "0123456" {
+		t.Fatalf(`the environment should be cached: got %v, wanted "0123456"`, env["sha"])
+	}
+	projectName := env["aws"].(map[string]interface{})["Codebuild"].(map[string]interface{})["ProjectName"] //nolint:forcetypeassert
+	if projectName != "hook-project" {
+		t.Fatalf(`got %v, wanted "hook-project"`, projectName)
+	}
+}
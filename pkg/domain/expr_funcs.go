@@ -2,75 +2,83 @@ package domain
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/google/go-github/v37/github"
 )
 
-// Functions and methods in this file is called at antonmedv/expr's program.
-// Basically we shouldn't use `panic` for error handling,
-// but in antonmedv/expr's program, `panic` is recommended.
-// Please see the author's message.
-//
-// https://github.com/antonmedv/expr/issues/66#issuecomment-526461353
-// > Hi, this is a good question. I think I forgot to mention this in docs. Expr expects functions to return a single value. To notify an error, you can use panic. Expr uses recover.
-// > For example, if your function going to divide by zero 1/0 Expr will also work correctly as it will panic.
+// Functions and methods in this file is called at antonmedv/expr's program and Go's text/template.
+// Both of them support functions which return a value and an error,
+// so functions return an error instead of panic.
+// The error is returned from the program as it is, so it can be handled with errors.As.
 
 func setExprFuncs(env map[string]interface{}) map[string]interface{} {
 	return env
 }
 
-func (data *Data) GetCommit() *github.Commit {
-	if cmt := data.Commit.Get(); cmt != nil {
-		return cmt
-	}
-	commit, err := data.GitHub.GetCommit(context.Background(), data.Repository.Owner, data.Repository.Name, data.SHA)
-	if err != nil {
-		panic(err)
-	}
-	data.Commit.Set(commit)
-	return commit
+func (data *Data) GetCommit() (*github.Commit, error) {
+	commit, err := data.commit(data.Context())
+	return commit, data.exprFuncError("getCommit", err)
 }
 
-func (data *Data) GetPRNumber() int {
-	if number := data.PullRequest.Number.Get(); number != 0 {
-		return number
-	}
+func (data *Data) commit(ctx context.Context) (*github.Commit, error) {
+	return data.Commit.Get(func() (*github.Commit, error) { //nolint:wrapcheck
+		commit, err := data.GitHub.GetCommit(ctx, data.Repository.Owner, data.Repository.Name, data.SHA)
+		if err != nil {
+			return nil, fmt.Errorf("get a commit (%s): %w", data.SHA, err)
+		}
+		return commit, nil
+	})
+}
+
+func (data *Data) GetPRNumber() (int, error) {
+	n, err := data.prNumber(data.Context())
+	return n, data.exprFuncError("getPRNumber", err)
+}
 
-	if pr := data.PullRequest.PullRequest.Get(); pr != nil {
-		number := pr.GetNumber()
-		data.PullRequest.Number.Set(number)
-		return number
-	}
+func (data *Data) prNumber(ctx context.Context) (int, error) {
+	return data.PullRequest.Number.Get(func() (int, error) { //nolint:wrapcheck
+		if pr, ok := data.PullRequest.PullRequest.Peek(); ok && pr != nil {
+			return pr.GetNumber(), nil
+		}
+		return getPRNumber(ctx, data.Repository.Owner, data.Repository.Name, data.SHA, data.GitHub)
+	})
+}
 
-	n, err := getPRNumber(context.Background(), data.Repository.Owner, data.Repository.Name, data.SHA, data.GitHub)
-	if err != nil {
-		panic(err)
-	}
-	data.PullRequest.Number.Set(n)
-	return n
+func (data *Data) GetPR() (*github.PullRequest, error) {
+	pr, err := data.pr(data.Context())
+	return pr, data.exprFuncError("getPR", err)
 }
 
-func (data *Data) GetPR() *github.PullRequest {
-	pr := data.PullRequest.PullRequest.Get()
-	if pr == nil {
-		p, err := data.GitHub.GetPR(context.Background(), data.Repository.Owner, data.Repository.Name, data.GetPRNumber())
+func (data *Data) pr(ctx context.Context) (*github.PullRequest, error) {
+	return data.PullRequest.PullRequest.Get(func() (*github.PullRequest, error) { //nolint:wrapcheck
+		number, err := data.prNumber(ctx)
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
-		pr = p
-		data.PullRequest.PullRequest.Set(pr)
-	}
-	return pr
+		pr, err := data.GitHub.GetPR(ctx, data.Repository.Owner, data.Repository.Name, number)
+		if err != nil {
+			return nil, fmt.Errorf("get a pull request (%d): %w", number, err)
+		}
+		return pr, nil
+	})
+}
+
+func (data *Data) GetPRFiles() ([]*github.CommitFile, error) {
+	files, err := data.prFiles(data.Context())
+	return files, data.exprFuncError("getPRFiles", err)
 }
 
-func (data *Data) GetPRFiles() []*github.CommitFile {
-	if files := data.PullRequest.Files.Get(); files != nil {
-		return files
-	}
-	files, err := getPRFiles(context.Background(), data.GitHub, data.Repository.Owner, data.Repository.Name, data.GetPRNumber(), data.GetPR().GetChangedFiles())
-	if err != nil {
-		panic(err)
-	}
-	data.PullRequest.Files.Set(files)
-	return files
+func (data *Data) prFiles(ctx context.Context) ([]*github.CommitFile, error) {
+	return data.PullRequest.Files.Get(func() ([]*github.CommitFile, error) { //nolint:wrapcheck
+		number, err := data.prNumber(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pr, err := data.pr(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return getPRFiles(ctx, data.GitHub, data.Repository.Owner, data.Repository.Name, number, pr.GetChangedFiles())
+	})
 }
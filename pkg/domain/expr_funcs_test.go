@@ -31,7 +31,10 @@ func TestData_GetCommit(t *testing.T) {
 		d := d
 		t.Run(d.title, func(t *testing.T) {
 			t.Parallel()
-			commit := d.data.GetCommit()
+			commit, err := d.data.GetCommit()
+			if err != nil {
+				t.Fatal(err)
+			}
 			if diff := cmp.Diff(*commit, *d.exp); diff != "" {
 				t.Fatalf(diff)
 			}
@@ -58,7 +61,10 @@ func TestData_GetPRNumber(t *testing.T) {
 		d := d
 		t.Run(d.title, func(t *testing.T) {
 			t.Parallel()
-			prNumber := d.data.GetPRNumber()
+			prNumber, err := d.data.GetPRNumber()
+			if err != nil {
+				t.Fatal(err)
+			}
 			if d.exp != prNumber {
 				t.Fatalf("got %d, wanted %d", prNumber, d.exp)
 			}
@@ -89,7 +95,10 @@ func TestData_GetPR(t *testing.T) {
 		d := d
 		t.Run(d.title, func(t *testing.T) {
 			t.Parallel()
-			pr := d.data.GetPR()
+			pr, err := d.data.GetPR()
+			if err != nil {
+				t.Fatal(err)
+			}
 			if diff := cmp.Diff(d.exp, pr); diff != "" {
 				t.Fatalf(diff)
 			}
@@ -124,7 +133,10 @@ func TestData_GetPRFiles(t *testing.T) {
 		d := d
 		t.Run(d.title, func(t *testing.T) {
 			t.Parallel()
-			files := d.data.GetPRFiles()
+			files, err := d.data.GetPRFiles()
+			if err != nil {
+				t.Fatal(err)
+			}
 			if diff := cmp.Diff(d.exp, files); diff != "" {
 				t.Fatalf(diff)
 			}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"github.com/google/go-github/v37/github"
+)
+
+// Statuses of files which GitHub API returns.
+// https://docs.github.com/en/rest/reference/pulls#list-pull-requests-files
+const (
+	fileStatusAdded    = "added"
+	fileStatusRemoved  = "removed"
+	fileStatusModified = "modified"
+	fileStatusRenamed  = "renamed"
+	// fileStatusChanged means only the file mode is changed.
+	fileStatusChanged = "changed"
+)
+
+// FileStat is a file changed by the pull request.
+// Unlike *github.CommitFile, fields aren't pointers so that they can be compared easily in expressions.
+type FileStat struct {
+	Name string
+	// PreviousName is the path before the file is renamed. If the file isn't renamed, PreviousName is empty.
+	PreviousName string
+	// Status is one of "added", "removed", "modified", "renamed", "copied", "changed", and "unchanged".
+	Status    string
+	Additions int
+	Deletions int
+	Changes   int
+}
+
+// extractFileNamesByStatus returns paths of files whose status is one of statuses.
+// For renamed files, the new paths are returned.
+func extractFileNamesByStatus(files []*github.CommitFile, statuses ...string) []string {
+	arr := []string{}
+	for _, file := range files {
+		for _, status := range statuses {
+			if file.GetStatus() == status {
+				arr = append(arr, file.GetFilename())
+				break
+			}
+		}
+	}
+	return arr
+}
+
+func extractFileStats(files []*github.CommitFile) []FileStat {
+	stats := make([]FileStat, len(files))
+	for i, file := range files {
+		stats[i] = FileStat{
+			Name:         file.GetFilename(),
+			PreviousName: file.GetPreviousFilename(),
+			Status:       file.GetStatus(),
+			Additions:    file.GetAdditions(),
+			Deletions:    file.GetDeletions(),
+			Changes:      file.GetChanges(),
+		}
+	}
+	return stats
+}
+
+// prFileNamesByStatus returns paths of the associated pull request's files whose status is one of statuses.
+func (data *Data) prFileNamesByStatus(funcName string, statuses ...string) ([]string, error) {
+	files, err := data.prFiles(data.Context())
+	if err != nil {
+		return nil, data.exprFuncError(funcName, err)
+	}
+	return extractFileNamesByStatus(files, statuses...), nil
+}
+
+func (data *Data) GetPRAddedFiles() ([]string, error) {
+	return data.prFileNamesByStatus("getPRAddedFiles", fileStatusAdded)
+}
+
+func (data *Data) GetPRRemovedFiles() ([]string, error) {
+	return data.prFileNamesByStatus("getPRRemovedFiles", fileStatusRemoved)
+}
+
+// GetPRModifiedFiles returns paths of files whose content or mode is modified.
+func (data *Data) GetPRModifiedFiles() ([]string, error) {
+	return data.prFileNamesByStatus("getPRModifiedFiles", fileStatusModified, fileStatusChanged)
+}
+
+// GetPRRenamedFiles returns the new paths of renamed files.
+// The previous paths can be got by GetPRFileStats.
+func (data *Data) GetPRRenamedFiles() ([]string, error) {
+	return data.prFileNamesByStatus("getPRRenamedFiles", fileStatusRenamed)
+}
+
+func (data *Data) GetPRFileStats() ([]FileStat, error) {
+	files, err := data.prFiles(data.Context())
+	if err != nil {
+		return nil, data.exprFuncError("getPRFileStats", err)
+	}
+	return extractFileStats(files), nil
+}
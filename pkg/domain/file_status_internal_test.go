@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v37/github"
+)
+
+func testPRFiles() []*github.CommitFile {
+	return []*github.CommitFile{
+		{
+			Filename:  github.String("migrations/001.sql"),
+			Status:    github.String("added"),
+			Additions: github.Int(10),
+			Changes:   github.Int(10),
+		},
+		{
+			Filename:  github.String("old.go"),
+			Status:    github.String("removed"),
+			Deletions: github.Int(5),
+			Changes:   github.Int(5),
+		},
+		{
+			Filename:  github.String("main.go"),
+			Status:    github.String("modified"),
+			Additions: github.Int(1),
+			Deletions: github.Int(2),
+			Changes:   github.Int(3),
+		},
+		{
+			Filename: github.String("script.sh"),
+			Status:   github.String("changed"),
+		},
+		{
+			Filename:         github.String("new.go"),
+			PreviousFilename: github.String("renamed.go"),
+			Status:           github.String("renamed"),
+		},
+	}
+}
+
+func Test_extractFileNamesByStatus(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title    string
+		statuses []string
+		exp      []string
+	}{
+		{
+			title:    "added",
+			statuses: []string{fileStatusAdded},
+			exp:      []string{"migrations/001.sql"},
+		},
+		{
+			title:    "removed",
+			statuses: []string{fileStatusRemoved},
+			exp:      []string{"old.go"},
+		},
+		{
+			title:    "modified",
+			statuses: []string{fileStatusModified, fileStatusChanged},
+			exp:      []string{"main.go", "script.sh"},
+		},
+		{
+			title:    "renamed",
+			statuses: []string{fileStatusRenamed},
+			exp:      []string{"new.go"},
+		},
+		{
+			title:    "no file",
+			statuses: []string{"copied"},
+			exp:      []string{},
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			names := extractFileNamesByStatus(testPRFiles(), d.statuses...)
+			if diff := cmp.Diff(d.exp, names); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func Test_extractFileStats(t *testing.T) {
+	t.Parallel()
+	stats := extractFileStats(testPRFiles())
+	exp := []FileStat{
+		{Name: "migrations/001.sql", Status: "added", Additions: 10, Changes: 10},
+		{Name: "old.go", Status: "removed", Deletions: 5, Changes: 5},
+		{Name: "main.go", Status: "modified", Additions: 1, Deletions: 2, Changes: 3},
+		{Name: "script.sh", Status: "changed"},
+		{Name: "new.go", PreviousName: "renamed.go", Status: "renamed"},
+	}
+	if diff := cmp.Diff(exp, stats); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestData_GetPRAddedFiles(t *testing.T) {
+	t.Parallel()
+	data := NewData()
+	data.PullRequest.Files.Set(testPRFiles())
+	names, err := data.GetPRAddedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"migrations/001.sql"}, names); diff != "" {
+		t.Fatal(diff)
+	}
+}
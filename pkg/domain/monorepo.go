@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v37/github"
+	"github.com/suzuki-shunsuke/lambuild/pkg/pathfilter"
+)
+
+// AnyFileMatches returns true if any file changed by the event matches any glob.
+// If GitHub API doesn't return all changed files, AnyFileMatches returns true so that builds aren't skipped wrongly.
+func (data *Data) AnyFileMatches(globs ...string) (bool, error) {
+	patterns := pathfilter.Patterns(globs)
+	if err := patterns.Validate(); err != nil {
+		return false, data.exprFuncError("anyFileMatches", err)
+	}
+	if len(patterns) == 0 {
+		return false, nil
+	}
+	files, complete, err := data.changedFileNames(data.Context())
+	if err != nil {
+		return false, data.exprFuncError("anyFileMatches", err)
+	}
+	if !complete {
+		return true, nil
+	}
+	return pathfilter.Match(patterns, nil, files), nil
+}
+
+// AllFilesMatch returns true if every file changed by the event matches any glob.
+// If no file is changed or GitHub API doesn't return all changed files, AllFilesMatch returns false.
+func (data *Data) AllFilesMatch(globs ...string) (bool, error) {
+	patterns := pathfilter.Patterns(globs)
+	if err := patterns.Validate(); err != nil {
+		return false, data.exprFuncError("allFilesMatch", err)
+	}
+	files, complete, err := data.changedFileNames(data.Context())
+	if err != nil {
+		return false, data.exprFuncError("allFilesMatch", err)
+	}
+	if !complete {
+		return false, nil
+	}
+	return pathfilter.MatchAll(patterns, files), nil
+}
+
+// errIncompleteFiles is returned if GitHub API doesn't return all changed files.
+var errIncompleteFiles = errors.New("GitHub API doesn't return all changed files")
+
+// ChangedDirs returns directories of files changed by the event.
+// Directories are truncated to depth. Files in the repository root are included as ".".
+// If GitHub API doesn't return all changed files, an error is returned because the result would be wrong.
+func (data *Data) ChangedDirs(depth int) ([]string, error) {
+	if depth < 1 {
+		return nil, data.exprFuncError("changedDirs", fmt.Errorf("depth must be greater than 0: %d", depth))
+	}
+	files, complete, err := data.changedFileNames(data.Context())
+	if err != nil {
+		return nil, data.exprFuncError("changedDirs", err)
+	}
+	if !complete {
+		return nil, data.exprFuncError("changedDirs", errIncompleteFiles)
+	}
+	return extractDirs(files, depth), nil
+}
+
+// ChangedModules returns directories which contain markerFile and files changed by the event.
+// For each changed file, the nearest directory containing markerFile is returned.
+// Directories containing markerFile are found from the repository tree at the event's commit, which is got by GitHub API only once per event.
+// If GitHub API doesn't return all changed files or the whole tree, an error is returned because the result would be wrong.
+func (data *Data) ChangedModules(markerFile string) ([]string, error) {
+	if markerFile == "" || strings.Contains(markerFile, "/") {
+		return nil, data.exprFuncError("changedModules", fmt.Errorf("marker file must be a file name: %s", markerFile))
+	}
+	ctx := data.Context()
+	files, complete, err := data.changedFileNames(ctx)
+	if err != nil {
+		return nil, data.exprFuncError("changedModules", err)
+	}
+	if !complete {
+		return nil, data.exprFuncError("changedModules", errIncompleteFiles)
+	}
+	tree, err := data.tree(ctx)
+	if err != nil {
+		return nil, data.exprFuncError("changedModules", err)
+	}
+	if tree.GetTruncated() {
+		return nil, data.exprFuncError("changedModules", errors.New("GitHub API doesn't return the whole tree of the repository"))
+	}
+	return findModuleDirs(files, extractMarkerDirs(tree, markerFile)), nil
+}
+
+// tree returns the tree of the repository at the event's commit.
+func (data *Data) tree(ctx context.Context) (*github.Tree, error) {
+	return data.Tree.Get(func() (*github.Tree, error) { //nolint:wrapcheck
+		tree, err := data.GitHub.GetTree(ctx, data.Repository.Owner, data.Repository.Name, data.SHA)
+		if err != nil {
+			return nil, fmt.Errorf("get a tree (%s): %w", data.SHA, err)
+		}
+		return tree, nil
+	})
+}
+
+// extractMarkerDirs returns directories which contain markerFile in the tree.
+func extractMarkerDirs(tree *github.Tree, markerFile string) map[string]struct{} {
+	dirs := map[string]struct{}{}
+	for _, entry := range tree.Entries {
+		if entry.GetType() != "blob" || path.Base(entry.GetPath()) != markerFile {
+			continue
+		}
+		dirs[path.Dir(entry.GetPath())] = struct{}{}
+	}
+	return dirs
+}
+
+// extractDirs returns sorted unique directories of files truncated to depth.
+func extractDirs(files []string, depth int) []string {
+	dirs := map[string]struct{}{}
+	for _, file := range files {
+		dir := path.Dir(file)
+		if dir != "." {
+			if elems := strings.Split(dir, "/"); len(elems) > depth {
+				dir = strings.Join(elems[:depth], "/")
+			}
+		}
+		dirs[dir] = struct{}{}
+	}
+	return sortedKeys(dirs)
+}
+
+// findModuleDirs returns sorted unique directories which are the nearest ancestors of files in moduleDirs.
+// Files which don't belong to any module are ignored.
+func findModuleDirs(files []string, moduleDirs map[string]struct{}) []string {
+	modules := map[string]struct{}{}
+	for _, file := range files {
+		for dir := path.Dir(file); ; dir = path.Dir(dir) {
+			if _, ok := moduleDirs[dir]; ok {
+				modules[dir] = struct{}{}
+				break
+			}
+			if dir == "." {
+				break
+			}
+		}
+	}
+	return sortedKeys(modules)
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	arr := make([]string, 0, len(m))
+	for k := range m {
+		arr = append(arr, k)
+	}
+	sort.Strings(arr)
+	return arr
+}
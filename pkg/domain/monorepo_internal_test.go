@@ -0,0 +1,245 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v37/github"
+)
+
+type treeClient struct {
+	GitHub
+	files []string
+	count int
+}
+
+func (client *treeClient) GetTree(ctx context.Context, owner, repo, sha string) (*github.Tree, error) {
+	client.count++
+	entries := make([]*github.TreeEntry, 0, len(client.files)+1)
+	entries = append(entries, &github.TreeEntry{
+		// directories are ignored
+		Path: github.String("go.mod"),
+		Type: github.String("tree"),
+	})
+	for _, file := range client.files {
+		entries = append(entries, &github.TreeEntry{
+			Path: github.String(file),
+			Type: github.String("blob"),
+		})
+	}
+	return &github.Tree{Entries: entries}, nil
+}
+
+func newMonorepoData(files []string, client GitHub) Data {
+	data := NewData()
+	data.PullRequest.ChangedFileNames.Set(files)
+	data.GitHub = client
+	return data
+}
+
+func TestData_AnyFileMatches(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title string
+		files []string
+		globs []string
+		exp   bool
+		isErr bool
+	}{
+		{
+			title: "match",
+			files: []string{"README.md", "services/api/main.go"},
+			globs: []string{"services/web/**", "services/api/**"},
+			exp:   true,
+		},
+		{
+			title: "not match",
+			files: []string{"README.md"},
+			globs: []string{"services/**"},
+		},
+		{
+			title: "no glob",
+			files: []string{"README.md"},
+		},
+		{
+			title: "invalid glob",
+			files: []string{"README.md"},
+			globs: []string{"services/[a"},
+			isErr: true,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			data := newMonorepoData(d.files, nil)
+			f, err := data.AnyFileMatches(d.globs...)
+			if err != nil {
+				if d.isErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if d.isErr {
+				t.Fatal("error should be returned")
+			}
+			if f != d.exp {
+				t.Fatalf("wanted %v, got %v", d.exp, f)
+			}
+		})
+	}
+}
+
+func TestData_AllFilesMatch(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title string
+		files []string
+		globs []string
+		exp   bool
+	}{
+		{
+			title: "all files match",
+			files: []string{"README.md", "docs/index.md"},
+			globs: []string{"**/*.md"},
+			exp:   true,
+		},
+		{
+			title: "some files don't match",
+			files: []string{"README.md", "main.go"},
+			globs: []string{"**/*.md"},
+		},
+		{
+			title: "no changed file",
+			files: []string{},
+			globs: []string{"**"},
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			data := newMonorepoData(d.files, nil)
+			f, err := data.AllFilesMatch(d.globs...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f != d.exp {
+				t.Fatalf("wanted %v, got %v", d.exp, f)
+			}
+		})
+	}
+}
+
+func Test_extractDirs(t *testing.T) {
+	t.Parallel()
+	files := []string{"README.md", "services/api/main.go", "services/api/handler/handler.go", "services/web/index.js", "docs/index.md"}
+	data := []struct {
+		title string
+		depth int
+		exp   []string
+	}{
+		{
+			title: "depth 1",
+			depth: 1,
+			exp:   []string{".", "docs", "services"},
+		},
+		{
+			title: "depth 2",
+			depth: 2,
+			exp:   []string{".", "docs", "services/api", "services/web"},
+		},
+		{
+			title: "depth 3",
+			depth: 3,
+			exp:   []string{".", "docs", "services/api", "services/api/handler", "services/web"},
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(d.exp, extractDirs(files, d.depth)); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestData_ChangedDirs_invalidDepth(t *testing.T) {
+	t.Parallel()
+	data := newMonorepoData([]string{"README.md"}, nil)
+	if _, err := data.ChangedDirs(0); err == nil {
+		t.Fatal("depth 0 should be rejected")
+	}
+}
+
+func TestData_ChangedModules(t *testing.T) {
+	t.Parallel()
+	client := &treeClient{
+		files: []string{
+			"go.mod",
+			"README.md",
+			"services/api/go.mod",
+			"services/api/main.go",
+			"services/worker/go.mod",
+			"services/web/package.json",
+		},
+	}
+	data := newMonorepoData([]string{
+		"README.md",
+		"services/api/main.go",
+		"services/api/handler/handler.go",
+		"services/web/index.js",
+	}, client)
+	modules, err := data.ChangedModules("go.mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{".", "services/api"}, modules); diff != "" {
+		t.Fatal(diff)
+	}
+	modules, err = data.ChangedModules("package.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"services/web"}, modules); diff != "" {
+		t.Fatal(diff)
+	}
+	// the tree is got only once
+	if client.count != 1 {
+		t.Fatalf("GitHub API should be called once, but called %d times", client.count)
+	}
+	if _, err := data.ChangedModules("services/go.mod"); err == nil {
+		t.Fatal("marker file must be a file name")
+	}
+}
+
+func TestData_ChangedModules_truncated(t *testing.T) {
+	t.Parallel()
+	data := newMonorepoData([]string{"README.md"}, nil)
+	data.Tree.Set(&github.Tree{Truncated: github.Bool(true)})
+	if _, err := data.ChangedModules("go.mod"); err == nil {
+		t.Fatal("error should be returned if the tree is truncated")
+	}
+}
+
+func TestData_ChangedDirs_incomplete(t *testing.T) {
+	t.Parallel()
+	data := NewData()
+	data.Event.Payload = &github.PushEvent{}
+	// GitHub API returns up to 300 files
+	files := make([]*github.CommitFile, maxCompareFiles)
+	for i := range files {
+		files[i] = &github.CommitFile{Filename: github.String(fmt.Sprintf("file-%d", i))}
+	}
+	data.Push.Files.Set(files)
+	if _, err := data.ChangedDirs(1); err == nil {
+		t.Fatal("error should be returned if changed files are incomplete")
+	}
+	if _, err := data.ChangedModules("go.mod"); err == nil {
+		t.Fatal("error should be returned if changed files are incomplete")
+	}
+}
@@ -18,7 +18,7 @@ func (boolExpr *Bool) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if err := unmarshal(&a); err != nil {
 		return fmt.Errorf("expression must be a string: %w", err)
 	}
-	prog, err := expr.Compile(a, expr.AsBool())
+	prog, err := compileBool(a)
 	if err != nil {
 		return fmt.Errorf("compile a program: %w", err)
 	}
@@ -27,7 +27,7 @@ func (boolExpr *Bool) UnmarshalYAML(unmarshal func(interface{}) error) error {
 }
 
 func NewBool(s string) (Bool, error) {
-	prog, err := expr.Compile(s, expr.AsBool())
+	prog, err := compileBool(s)
 	if err != nil {
 		return Bool{}, fmt.Errorf("compile a program: %w", err)
 	}
@@ -17,9 +17,9 @@ func TestBool_UnmarshalYAML(t *testing.T) {
 	}{
 		{
 			title: "normal",
-			yaml:  `name == "foo"`,
+			yaml:  `sha == "foo"`,
 			param: map[string]interface{}{
-				"name": "foo",
+				"sha": "foo",
 			},
 			exp: true,
 		},
@@ -71,3 +71,29 @@ func TestNewBool(t *testing.T) {
 		t.Fatal("Bool must be false")
 	}
 }
+
+func TestNewBool_invalid(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title      string
+		expression string
+	}{
+		{
+			title:      "unknown function",
+			expression: `"foo" in getPRLables()`,
+		},
+		{
+			title:      "not bool",
+			expression: `getPRNumber()`,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			if _, err := expr.NewBool(d.expression); err == nil {
+				t.Fatal("expression should be rejected")
+			}
+		})
+	}
+}
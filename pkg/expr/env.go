@@ -0,0 +1,51 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/checker"
+	"github.com/antonmedv/expr/compiler"
+	"github.com/antonmedv/expr/conf"
+	"github.com/antonmedv/expr/file"
+	"github.com/antonmedv/expr/optimizer"
+	"github.com/antonmedv/expr/parser"
+	"github.com/antonmedv/expr/vm"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+)
+
+// compileBool compiles a boolean expression.
+// Unknown identifiers and non boolean results are rejected.
+func compileBool(s string) (*vm.Program, error) {
+	return expr.Compile(s, expr.Env(domain.ExprEnv()), expr.AsBool()) //nolint:wrapcheck
+}
+
+// compileString compiles a string expression.
+// Unknown identifiers and results which can't be a string are rejected.
+// Results whose types are unknown at compile time such as item's parameters are checked when the expression is evaluated.
+// The expression is parsed and checked once, and the type of the result is got from the check.
+// The steps are same as expr.Compile.
+func compileString(s string) (*vm.Program, error) {
+	config := conf.New(domain.ExprEnv())
+	tree, err := parser.Parse(s)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	t, err := checker.Check(tree, config)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if k := t.Kind(); k != reflect.String && k != reflect.Interface {
+		return nil, fmt.Errorf("expected string, but got %v", t)
+	}
+	if err := optimizer.Optimize(&tree.Node, config); err != nil {
+		var fileErr *file.Error
+		if errors.As(err, &fileErr) {
+			return nil, fileErr.Bind(tree.Source)
+		}
+		return nil, err //nolint:wrapcheck
+	}
+	return compiler.Compile(tree, config) //nolint:wrapcheck
+}
@@ -18,7 +18,7 @@ func (str *String) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if err := unmarshal(&a); err != nil {
 		return fmt.Errorf("expression must be a string: %w", err)
 	}
-	prog, err := expr.Compile(a)
+	prog, err := compileString(a)
 	if err != nil {
 		return fmt.Errorf("compile a program: %w", err)
 	}
@@ -27,7 +27,7 @@ func (str *String) UnmarshalYAML(unmarshal func(interface{}) error) error {
 }
 
 func NewString(s string) (String, error) {
-	prog, err := expr.Compile(s)
+	prog, err := compileString(s)
 	if err != nil {
 		return String{}, fmt.Errorf("compile a program: %w", err)
 	}
@@ -17,9 +17,9 @@ func TestString_UnmarshalYAML(t *testing.T) {
 	}{
 		{
 			title: "normal",
-			yaml:  `name`,
+			yaml:  `sha`,
 			param: map[string]interface{}{
-				"name": "foo",
+				"sha": "foo",
 			},
 			exp: "foo",
 		},
@@ -68,3 +68,48 @@ func TestNewString(t *testing.T) {
 		t.Fatal(`String must be "foo"`)
 	}
 }
+
+func TestNewString_invalid(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title      string
+		expression string
+	}{
+		{
+			title:      "unknown name",
+			expression: `shaa`,
+		},
+		{
+			title:      "not string",
+			expression: `getPRNumber()`,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			if _, err := expr.NewString(d.expression); err == nil {
+				t.Fatal("expression should be rejected")
+			}
+		})
+	}
+}
+
+func TestNewString_item(t *testing.T) {
+	t.Parallel()
+	b, err := expr.NewString(`item.name`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := b.Run(map[string]interface{}{
+		"item": map[string]interface{}{
+			"name": "foo",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "foo" {
+		t.Fatalf(`got "%s", wanted "foo"`, s)
+	}
+}
@@ -0,0 +1,206 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v37/github"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// jwtExpiration is the expiration of JWT. GitHub allows up to 10 minutes.
+	jwtExpiration = 9 * time.Minute
+	// jwtClockDrift is subtracted from the issued time to allow the clock drift between lambuild and GitHub.
+	jwtClockDrift = 60 * time.Second
+)
+
+// App is a GitHub App.
+// App creates GitHub clients authenticated as the GitHub App's installations,
+// and caches installation access tokens per installation until they expire.
+type App struct {
+	id      int64
+	key     *rsa.PrivateKey
+	baseURL *url.URL
+	client  *github.Client
+	clients map[int64]*Client
+	mutex   *sync.Mutex
+	now     func() time.Time
+}
+
+type AppParam struct {
+	ID         string
+	PrivateKey string
+	// BaseURL is GitHub API's base URL. BaseURL is used in tests.
+	// If BaseURL is empty, https://api.github.com/ is used.
+	BaseURL string
+}
+
+func NewApp(param AppParam) (*App, error) {
+	id, err := strconv.ParseInt(param.ID, 10, 64) //nolint:gomnd
+	if err != nil {
+		return nil, fmt.Errorf("parse GitHub App ID as int64 (%s): %w", param.ID, err)
+	}
+	key, err := parsePrivateKey([]byte(param.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("parse GitHub App private key: %w", err)
+	}
+	app := &App{
+		id:      id,
+		key:     key,
+		clients: map[int64]*Client{},
+		mutex:   &sync.Mutex{},
+		now:     time.Now,
+	}
+	app.client = github.NewClient(&http.Client{
+		Transport: &jwtTransport{app: app},
+	})
+	if param.BaseURL != "" {
+		u, err := url.Parse(param.BaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse GitHub API base URL (%s): %w", param.BaseURL, err)
+		}
+		app.baseURL = u
+		app.client.BaseURL = u
+	}
+	return app, nil
+}
+
+func parsePrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("private key must be PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key as PKCS1 or PKCS8: %w", err)
+	}
+	key, ok := k.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key must be RSA private key")
+	}
+	return key, nil
+}
+
+// Installation returns a client authenticated as the installation.
+// The client is cached per installation, and the installation access token is refreshed when it expires.
+func (app *App) Installation(installationID int64) *Client {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	if client, ok := app.clients[installationID]; ok {
+		return client
+	}
+	ghClient := github.NewClient(&http.Client{
+		Transport: &installationTransport{
+			app:            app,
+			installationID: installationID,
+			mutex:          &sync.Mutex{},
+		},
+	})
+	if app.baseURL != nil {
+		ghClient.BaseURL = app.baseURL
+	}
+	client := &Client{client: ghClient}
+	app.clients[installationID] = client
+	return client
+}
+
+// createJWT creates a JWT to authenticate as the GitHub App.
+// https://docs.github.com/en/developers/apps/building-github-apps/authenticating-with-github-apps#authenticating-as-a-github-app
+func (app *App) createJWT() (string, error) {
+	now := app.now()
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal JWT header: %w", err)
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-jwtClockDrift).Unix(),
+		"exp": now.Add(jwtExpiration).Unix(),
+		"iss": app.id,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal JWT claims: %w", err)
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, app.key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("sign JWT: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// jwtTransport sets a JWT to the Authorization header.
+type jwtTransport struct {
+	app *App
+}
+
+func (transport *jwtTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := transport.app.createJWT()
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+token)
+	return http.DefaultTransport.RoundTrip(r) //nolint:wrapcheck
+}
+
+// installationTransport sets an installation access token to the Authorization header.
+// The token is created with the request's context, so the creation is canceled with the request.
+// The token is cached until it expires.
+type installationTransport struct {
+	app            *App
+	installationID int64
+	token          *oauth2.Token
+	mutex          *sync.Mutex
+}
+
+func (transport *installationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := transport.getToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	token.SetAuthHeader(r)
+	return http.DefaultTransport.RoundTrip(r) //nolint:wrapcheck
+}
+
+// getToken returns the cached installation access token.
+// If the token isn't cached or has expired, a new token is created.
+func (transport *installationTransport) getToken(ctx context.Context) (*oauth2.Token, error) {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	if transport.token.Valid() {
+		return transport.token, nil
+	}
+	token, _, err := transport.app.client.Apps.CreateInstallationToken(ctx, transport.installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create an installation access token by GitHub API (installation_id: %d): %w", transport.installationID, err)
+	}
+	transport.token = &oauth2.Token{
+		AccessToken: token.GetToken(),
+		TokenType:   "token",
+		Expiry:      token.GetExpiresAt(),
+	}
+	return transport.token, nil
+}
@@ -0,0 +1,126 @@
+package github_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gh "github.com/suzuki-shunsuke/lambuild/pkg/github"
+)
+
+func verifyJWT(t *testing.T, key *rsa.PublicKey, token string) error {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 { //nolint:gomnd
+		return fmt.Errorf("JWT must have 3 parts: %s", token)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("decode a signature: %w", err)
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], sig); err != nil {
+		return fmt.Errorf("verify a signature: %w", err)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fmt.Errorf("decode claims: %w", err)
+	}
+	claims := map[string]interface{}{}
+	if err := json.Unmarshal(b, &claims); err != nil {
+		return fmt.Errorf("unmarshal claims: %w", err)
+	}
+	if claims["iss"] != float64(123) {
+		return fmt.Errorf("iss must be 123: %v", claims["iss"])
+	}
+	return nil
+}
+
+func TestApp_Installation(t *testing.T) {
+	t.Parallel()
+	key, err := rsa.GenerateKey(rand.Reader, 2048) //nolint:gomnd
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+
+	var tokenCount int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/app/installations/456/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		if err := verifyJWT(t, &key.PublicKey, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		atomic.AddInt32(&tokenCount, 1)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token": "installation-token", "expires_at": %q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+	})
+	mux.HandleFunc("/repos/suzuki-shunsuke/lambuild/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token installation-token" {
+			http.Error(w, "invalid token: "+r.Header.Get("Authorization"), http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"number": 1}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	app, err := gh.NewApp(gh.AppParam{
+		ID:         "123",
+		PrivateKey: privateKey,
+		BaseURL:    server.URL + "/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the installation access token is created with the request's context
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := app.Installation(456).GetPR(canceledCtx, "suzuki-shunsuke", "lambuild", 1); err == nil {
+		t.Fatal("the request with the canceled context should fail")
+	}
+	for i := 0; i < 2; i++ {
+		pr, err := app.Installation(456).GetPR(context.Background(), "suzuki-shunsuke", "lambuild", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pr.GetNumber() != 1 {
+			t.Fatalf("got %d, wanted 1", pr.GetNumber())
+		}
+	}
+	if tokenCount != 1 {
+		t.Fatalf("an installation access token should be cached, but it was created %d times", tokenCount)
+	}
+}
+
+func TestNewApp(t *testing.T) {
+	t.Parallel()
+	if _, err := gh.NewApp(gh.AppParam{
+		ID:         "foo",
+		PrivateKey: "",
+	}); err == nil {
+		t.Fatal("invalid App ID should be rejected")
+	}
+	if _, err := gh.NewApp(gh.AppParam{
+		ID:         "123",
+		PrivateKey: "foo",
+	}); err == nil {
+		t.Fatal("invalid private key should be rejected")
+	}
+}
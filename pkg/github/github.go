@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/google/go-github/v37/github"
 	"golang.org/x/oauth2"
@@ -60,6 +61,15 @@ func (client *Client) GetContents(ctx context.Context, owner, repo, path, ref st
 	return file, files, nil
 }
 
+// GetTree returns the tree of the commit recursively.
+func (client *Client) GetTree(ctx context.Context, owner, repo, sha string) (*github.Tree, error) {
+	tree, _, err := client.client.Git.GetTree(ctx, owner, repo, sha, true)
+	if err != nil {
+		return nil, fmt.Errorf("get a tree by GitHub API: %w", err)
+	}
+	return tree, nil
+}
+
 func (client *Client) CreateCommitComment(ctx context.Context, owner, repo, sha, body string) error {
 	if _, _, err := client.client.Repositories.CreateComment(ctx, owner, repo, sha, &github.RepositoryComment{
 		Body: github.String(body),
@@ -77,3 +87,52 @@ func (client *Client) CreatePRComment(ctx context.Context, owner, repo string, n
 	}
 	return nil
 }
+
+// RemoveLabel removes the label from the pull request or issue.
+// If the label has already been removed, RemoveLabel returns nil.
+func (client *Client) RemoveLabel(ctx context.Context, owner, repo string, number int, label string) error {
+	resp, err := client.client.Issues.RemoveLabelForIssue(ctx, owner, repo, number, label)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return fmt.Errorf("remove a label by GitHub API: %w", err)
+	}
+	return nil
+}
+
+func (client *Client) GetPermissionLevel(ctx context.Context, owner, repo, user string) (string, error) {
+	level, _, err := client.client.Repositories.GetPermissionLevel(ctx, owner, repo, user)
+	if err != nil {
+		return "", fmt.Errorf("get a repository permission level of a user by GitHub API: %w", err)
+	}
+	return level.GetPermission(), nil
+}
+
+func (client *Client) CompareCommits(ctx context.Context, owner, repo, base, head string) (*github.CommitsComparison, error) {
+	comparison, _, err := client.client.Repositories.CompareCommits(ctx, owner, repo, base, head)
+	if err != nil {
+		return nil, fmt.Errorf("compare two commits by GitHub API: %w", err)
+	}
+	return comparison, nil
+}
+
+func (client *Client) ListReviews(ctx context.Context, owner, repo string, number int, opt *github.ListOptions) ([]*github.PullRequestReview, error) {
+	reviews, _, err := client.client.PullRequests.ListReviews(ctx, owner, repo, number, opt)
+	if err != nil {
+		return nil, fmt.Errorf("list pull request reviews by GitHub API: %w", err)
+	}
+	return reviews, nil
+}
+
+// IsTeamMember returns true if the user is an active member of the team.
+func (client *Client) IsTeamMember(ctx context.Context, org, teamSlug, user string) (bool, error) {
+	membership, resp, err := client.client.Teams.GetTeamMembershipBySlug(ctx, org, teamSlug, user)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("get a team membership by GitHub API: %w", err)
+	}
+	return membership.GetState() == "active", nil
+}
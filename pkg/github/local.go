@@ -0,0 +1,202 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-github/v37/github"
+)
+
+// Local is a GitHub client which reads data from a local checkout directory and fixtures instead of calling GitHub API.
+// Local is used to render build inputs without GitHub API and AWS.
+// Comments aren't sent but written to Stderr.
+type Local struct {
+	// Dir is the local checkout directory of the repository.
+	Dir string
+	// PR is returned by GetPR and GetPRsWithCommit. PR may be nil.
+	PR *github.PullRequest
+	// PRFiles is returned by GetPRFiles. If PRFiles is nil, GetPRFiles returns an error.
+	PRFiles []*github.CommitFile
+	// Commit is returned by GetCommit. If Commit is nil, GetCommit returns an error.
+	Commit *github.Commit
+	// PushFiles is returned by CompareCommits. If PushFiles is nil, CompareCommits returns an error.
+	PushFiles []*github.CommitFile
+	// Reviews is returned by ListReviews.
+	Reviews []*github.PullRequestReview
+	// Stderr is the output of comments and label removals. If Stderr is nil, they are discarded.
+	Stderr io.Writer
+}
+
+var errNotSupportedInLocal = errors.New("this isn't supported in the local mode")
+
+func (client *Local) GetCommit(ctx context.Context, owner, repo, sha string) (*github.Commit, error) {
+	if client.Commit == nil {
+		return nil, fmt.Errorf("get a commit (%s): %w", sha, errNotSupportedInLocal)
+	}
+	return client.Commit, nil
+}
+
+func (client *Local) GetPR(ctx context.Context, owner, repo string, number int) (*github.PullRequest, error) {
+	if client.PR == nil || client.PR.GetNumber() != number {
+		return nil, fmt.Errorf("get a pull request (%d): %w", number, errNotSupportedInLocal)
+	}
+	return client.PR, nil
+}
+
+func (client *Local) GetPRFiles(ctx context.Context, owner, repo string, number int, opt *github.ListOptions) ([]*github.CommitFile, error) {
+	if client.PRFiles == nil {
+		return nil, fmt.Errorf("get pull request files (%d): %w", number, errNotSupportedInLocal)
+	}
+	if opt == nil || opt.PerPage == 0 {
+		return client.PRFiles, nil
+	}
+	page := opt.Page
+	if page == 0 {
+		page = 1
+	}
+	start := (page - 1) * opt.PerPage
+	if start >= len(client.PRFiles) {
+		return []*github.CommitFile{}, nil
+	}
+	end := start + opt.PerPage
+	if end > len(client.PRFiles) {
+		end = len(client.PRFiles)
+	}
+	return client.PRFiles[start:end], nil
+}
+
+func (client *Local) GetPRsWithCommit(ctx context.Context, owner, repo string, sha string) ([]*github.PullRequest, error) {
+	if client.PR == nil {
+		return nil, nil
+	}
+	return []*github.PullRequest{client.PR}, nil
+}
+
+// GetContents reads a file or files in a directory from the local checkout directory.
+// The ref is ignored.
+func (client *Local) GetContents(ctx context.Context, owner, repo, path, ref string) (*github.RepositoryContent, []*github.RepositoryContent, error) {
+	p := filepath.Join(client.Dir, filepath.FromSlash(path))
+	finfo, err := os.Stat(p)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get a file (%s): %w", path, err)
+	}
+	if !finfo.IsDir() {
+		content, err := readLocalContent(p, path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return content, nil, nil
+	}
+	entries, err := ioutil.ReadDir(p)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read a directory (%s): %w", path, err)
+	}
+	files := make([]*github.RepositoryContent, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		content, err := readLocalContent(filepath.Join(p, entry.Name()), filepath.ToSlash(filepath.Join(path, entry.Name())))
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, content)
+	}
+	return nil, files, nil
+}
+
+// GetTree returns files in the local checkout directory as the tree.
+// The sha is ignored, and the .git directory is skipped.
+func (client *Local) GetTree(ctx context.Context, owner, repo, sha string) (*github.Tree, error) {
+	entries := []*github.TreeEntry{}
+	if err := filepath.WalkDir(client.Dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(client.Dir, p)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		entries = append(entries, &github.TreeEntry{
+			Path: github.String(filepath.ToSlash(rel)),
+			Type: github.String("blob"),
+		})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("walk the directory (%s): %w", client.Dir, err)
+	}
+	return &github.Tree{
+		Entries:   entries,
+		Truncated: github.Bool(false),
+	}, nil
+}
+
+func readLocalContent(p, path string) (*github.RepositoryContent, error) {
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("read a file (%s): %w", path, err)
+	}
+	return &github.RepositoryContent{
+		Type:    github.String("file"),
+		Name:    github.String(filepath.Base(p)),
+		Path:    github.String(path),
+		Content: github.String(string(b)),
+	}, nil
+}
+
+func (client *Local) CreateCommitComment(ctx context.Context, owner, repo, sha, body string) error {
+	if client.Stderr != nil {
+		fmt.Fprintf(client.Stderr, "[comment to the commit %s]\n%s\n", sha, body)
+	}
+	return nil
+}
+
+func (client *Local) CreatePRComment(ctx context.Context, owner, repo string, number int, body string) error {
+	if client.Stderr != nil {
+		fmt.Fprintf(client.Stderr, "[comment to the pull request #%d]\n%s\n", number, body)
+	}
+	return nil
+}
+
+func (client *Local) RemoveLabel(ctx context.Context, owner, repo string, number int, label string) error {
+	if client.Stderr != nil {
+		fmt.Fprintf(client.Stderr, "[remove the label %s from the pull request #%d]\n", label, number)
+	}
+	return nil
+}
+
+func (client *Local) GetPermissionLevel(ctx context.Context, owner, repo, user string) (string, error) {
+	return "admin", nil
+}
+
+func (client *Local) CompareCommits(ctx context.Context, owner, repo, base, head string) (*github.CommitsComparison, error) {
+	if client.PushFiles == nil {
+		return nil, fmt.Errorf("compare two commits (%s...%s): %w", base, head, errNotSupportedInLocal)
+	}
+	return &github.CommitsComparison{
+		Files: client.PushFiles,
+	}, nil
+}
+
+func (client *Local) ListReviews(ctx context.Context, owner, repo string, number int, opt *github.ListOptions) ([]*github.PullRequestReview, error) {
+	if opt == nil || opt.Page <= 1 {
+		return client.Reviews, nil
+	}
+	return nil, nil
+}
+
+func (client *Local) IsTeamMember(ctx context.Context, org, teamSlug, user string) (bool, error) {
+	return false, fmt.Errorf("get a team membership (%s/%s): %w", org, teamSlug, errNotSupportedInLocal)
+}
@@ -0,0 +1,108 @@
+package github_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v37/github"
+	gh "github.com/suzuki-shunsuke/lambuild/pkg/github"
+)
+
+func TestLocal_GetContents(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "lambuild"), 0o755); err != nil { //nolint:gomnd
+		t.Fatal(err)
+	}
+	for _, p := range []string{"lambuild.yaml", "lambuild/foo.yaml", "lambuild/bar.yaml"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, p), []byte("version: 0.2"), 0o644); err != nil { //nolint:gomnd
+			t.Fatal(err)
+		}
+	}
+	client := &gh.Local{Dir: dir}
+	ctx := context.Background()
+
+	file, files, err := client.GetContents(ctx, "", "", "lambuild.yaml", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files != nil {
+		t.Fatal("files should be nil")
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != "version: 0.2" {
+		t.Fatalf(`got %s, wanted "version: 0.2"`, content)
+	}
+
+	file, files, err = client.GetContents(ctx, "", "", "lambuild", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file != nil {
+		t.Fatal("file should be nil")
+	}
+	if len(files) != 2 { //nolint:gomnd
+		t.Fatalf("got %d files, wanted 2", len(files))
+	}
+	if files[0].GetPath() != "lambuild/bar.yaml" {
+		t.Fatalf(`got %s, wanted "lambuild/bar.yaml"`, files[0].GetPath())
+	}
+
+	if _, _, err := client.GetContents(ctx, "", "", "not_found.yaml", ""); err == nil {
+		t.Fatal("error should be returned if the file isn't found")
+	}
+}
+
+func TestLocal_GetTree(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	for _, d := range []string{".git", "services/api"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0o755); err != nil { //nolint:gomnd
+			t.Fatal(err)
+		}
+	}
+	for _, p := range []string{".git/HEAD", "go.mod", "services/api/go.mod"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, p), []byte(""), 0o644); err != nil { //nolint:gomnd
+			t.Fatal(err)
+		}
+	}
+	client := &gh.Local{Dir: dir}
+	tree, err := client.GetTree(context.Background(), "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make([]string, len(tree.Entries))
+	for i, entry := range tree.Entries {
+		paths[i] = entry.GetPath()
+	}
+	if diff := cmp.Diff([]string{"go.mod", "services/api/go.mod"}, paths); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestLocal_GetPRFiles(t *testing.T) {
+	t.Parallel()
+	client := &gh.Local{}
+	if _, err := client.GetPRFiles(context.Background(), "", "", 1, nil); err == nil {
+		t.Fatal("error should be returned if PRFiles isn't set")
+	}
+	client.PRFiles = []*github.CommitFile{
+		{Filename: github.String("foo")},
+		{Filename: github.String("bar")},
+		{Filename: github.String("zoo")},
+	}
+	files, err := client.GetPRFiles(context.Background(), "", "", 1, &github.ListOptions{Page: 2, PerPage: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].GetFilename() != "zoo" {
+		t.Fatalf("got %v, wanted [zoo]", files)
+	}
+}
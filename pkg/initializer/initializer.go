@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -12,8 +13,10 @@ import (
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
 	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/sirupsen/logrus"
 	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
 	gh "github.com/suzuki-shunsuke/lambuild/pkg/github"
 	"github.com/suzuki-shunsuke/lambuild/pkg/lambda"
 	"github.com/suzuki-shunsuke/lambuild/pkg/template"
@@ -30,7 +33,7 @@ func InitializeHandler(ctx context.Context, handler *lambda.Handler) error {
 		logrus.SetLevel(lvl)
 	}
 
-	if err := validateRepositories(cfg.Repositories); err != nil {
+	if err := validateRepositories(cfg.Repositories, cfg.Organizations); err != nil {
 		return fmt.Errorf("validate repositories: %w", err)
 	}
 
@@ -49,6 +52,14 @@ func InitializeHandler(ctx context.Context, handler *lambda.Handler) error {
 		cfg.SSMParameter.ParameterName.WebhookSecret = os.Getenv("SSM_PARAMETER_NAME_WEBHOOK_SECRET")
 	}
 
+	if cfg.SSMParameter.ParameterName.GitHubAppID == "" {
+		cfg.SSMParameter.ParameterName.GitHubAppID = os.Getenv("SSM_PARAMETER_NAME_GITHUB_APP_ID")
+	}
+
+	if cfg.SSMParameter.ParameterName.GitHubAppPrivateKey == "" {
+		cfg.SSMParameter.ParameterName.GitHubAppPrivateKey = os.Getenv("SSM_PARAMETER_NAME_GITHUB_APP_PRIVATE_KEY")
+	}
+
 	if cfg.SecretsManager.SecretID == "" {
 		cfg.SecretsManager.SecretID = os.Getenv("SECRETS_MANAGER_SECRET_ID")
 	}
@@ -59,7 +70,7 @@ func InitializeHandler(ctx context.Context, handler *lambda.Handler) error {
 
 	if cfg.BuildStatusContext.Empty() {
 		if cntxt := os.Getenv("BUILD_STATUS_CONTEXT"); cntxt != "" {
-			tpl, err := template.New(cntxt)
+			tpl, err := template.NewTrusted(cntxt)
 			if err != nil {
 				return fmt.Errorf("parse BUILD_STATUS_CONTEXT as template (%s): %w", cntxt, err)
 			}
@@ -71,11 +82,15 @@ func InitializeHandler(ctx context.Context, handler *lambda.Handler) error {
 		return fmt.Errorf("configure error notification template: %w", err)
 	}
 
+	if err := cfg.CompileTemplates(); err != nil {
+		return fmt.Errorf("compile templates: %w", err)
+	}
+
 	handler.Config = cfg
 
 	sess := session.Must(session.NewSession())
 	switch {
-	case cfg.SSMParameter.ParameterName.GitHubToken != "":
+	case cfg.SSMParameter.ParameterName.WebhookSecret != "":
 		ssmSvc := ssm.New(sess, aws.NewConfig().WithRegion(handler.Config.Region))
 		secret, err := readSecretFromSSM(ctx, ssmSvc, handler.Config.SSMParameter.ParameterName)
 		if err != nil {
@@ -93,8 +108,9 @@ func InitializeHandler(ctx context.Context, handler *lambda.Handler) error {
 		return errors.New("secrets aren't configured")
 	}
 
-	ghClient := gh.New(ctx, handler.Secret.GitHubToken)
-	handler.GitHub = &ghClient
+	if err := setGitHub(ctx, handler); err != nil {
+		return fmt.Errorf("configure a GitHub client: %w", err)
+	}
 	handler.CodeBuild = codebuild.New(sess, aws.NewConfig().WithRegion(handler.Config.Region))
 
 	// get AWS Account ID
@@ -108,21 +124,147 @@ func InitializeHandler(ctx context.Context, handler *lambda.Handler) error {
 	return nil
 }
 
-func validateRepositories(repos []config.Repository) error {
+// gitHubApp is an adapter of gh.App to lambda.GitHubApp.
+type gitHubApp struct {
+	app *gh.App
+}
+
+func (app *gitHubApp) Installation(installationID int64) domain.GitHub {
+	return app.app.Installation(installationID)
+}
+
+// setGitHub sets a GitHub client to the handler.
+// If GitHub App is configured, lambuild is authenticated as GitHub App's installations.
+// Otherwise, lambuild is authenticated with the GitHub Access Token.
+func setGitHub(ctx context.Context, handler *lambda.Handler) error {
+	secret := handler.Secret
+	if secret.GitHubAppID != "" || secret.GitHubAppPrivateKey != "" {
+		if secret.GitHubAppID == "" || secret.GitHubAppPrivateKey == "" {
+			return errors.New("both GitHub App ID and private key are required")
+		}
+		app, err := gh.NewApp(gh.AppParam{
+			ID:         secret.GitHubAppID,
+			PrivateKey: secret.GitHubAppPrivateKey,
+		})
+		if err != nil {
+			return fmt.Errorf("initialize GitHub App: %w", err)
+		}
+		handler.GitHubApp = &gitHubApp{app: app}
+		return nil
+	}
+	if secret.GitHubToken == "" {
+		return errors.New("either GitHub Access Token or GitHub App is required")
+	}
+	ghClient := gh.New(ctx, secret.GitHubToken)
+	handler.GitHub = &ghClient
+	return nil
+}
+
+func validateRepositories(repos []config.Repository, orgs []config.Organization) error {
 	if len(repos) == 0 {
 		return errors.New(`the configuration 'repositories' is required`)
 	}
+	if err := validateOrganizations(orgs); err != nil {
+		return err
+	}
 	for _, repo := range repos {
-		if repo.Name == "" {
-			return errors.New(`the repository 'name' is required`)
+		if repo.Name == "" && repo.NameRegexp.Empty() {
+			return errors.New(`the repository 'name' or 'name-regexp' is required`)
 		}
-		if repo.CodeBuild.ProjectName == "" {
+		if repo.Name != "" && !repo.NameRegexp.Empty() {
+			return fmt.Errorf(`the repository 'name' and 'name-regexp' can't be used at the same time (repo: %s)`, repo.Name)
+		}
+		if _, err := path.Match(repo.Name, ""); err != nil {
+			return fmt.Errorf(`the repository 'name' is an invalid glob pattern (repo: %s): %w`, repo.Name, err)
+		}
+		// if the repository may inherit the organization's project name, the project name is checked when the event is handled
+		if repo.CodeBuild.ProjectName == "" && len(orgs) == 0 {
 			for _, hook := range repo.Hooks {
 				if hook.ProjectName == "" {
 					return fmt.Errorf(`'project-name' is required (repo: %s)`, repo.Name)
 				}
 			}
 		}
+		if repo.ForkPullRequest.Policy.Get() == config.ForkPolicyRequireLabel && repo.ForkPullRequest.Label == "" {
+			return fmt.Errorf(`'fork-pull-request.label' is required if the policy is require-label (repo: %s)`, repo.Name)
+		}
+		switch repo.HookMode {
+		case "", config.HookModeFirst, config.HookModeAll:
+		default:
+			return fmt.Errorf(`'hook-mode' must be either first or all (repo: %s): %s`, repo.Name, repo.HookMode)
+		}
+		if err := validateAuthorization(repo.Authorization); err != nil {
+			return fmt.Errorf("validate authorization (repo: %s): %w", repo.Name, err)
+		}
+		if err := validateHooks(repo.Hooks); err != nil {
+			return fmt.Errorf("validate hooks (repo: %s): %w", repo.Name, err)
+		}
+		if err := validateBuildPolicy(repo.BuildPolicy); err != nil {
+			return fmt.Errorf("validate build-policy (repo: %s): %w", repo.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateOrganizations(orgs []config.Organization) error {
+	names := make(map[string]struct{}, len(orgs))
+	for _, org := range orgs {
+		if org.Name == "" {
+			return errors.New(`the organization 'name' is required`)
+		}
+		if _, ok := names[org.Name]; ok {
+			return fmt.Errorf(`the organization is duplicated: %s`, org.Name)
+		}
+		names[org.Name] = struct{}{}
+		if err := validateHooks(org.Hooks); err != nil {
+			return fmt.Errorf("validate hooks (organization: %s): %w", org.Name, err)
+		}
+		if err := validateBuildPolicy(org.BuildPolicy); err != nil {
+			return fmt.Errorf("validate build-policy (organization: %s): %w", org.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateHooks(hooks []config.Hook) error {
+	for _, hook := range hooks {
+		allowed := hook.AllowedOverrides
+		for _, patterns := range [][]string{
+			allowed.EncryptionKeys, allowed.SourceAuthResources, allowed.SecondarySourceLocations,
+			allowed.ArtifactLocations, allowed.CacheLocations, allowed.LogLocations,
+		} {
+			for _, pattern := range patterns {
+				if !doublestar.ValidatePattern(pattern) {
+					return fmt.Errorf("allowed-overrides has an invalid glob pattern: %s", pattern)
+				}
+			}
+		}
+		if err := validateBuildPolicy(hook.BuildPolicy); err != nil {
+			return fmt.Errorf("validate build-policy (hook: %s): %w", hook.Config, err)
+		}
+	}
+	return nil
+}
+
+func validateBuildPolicy(policy config.BuildPolicy) error {
+	for _, pattern := range policy.Images {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("images has an invalid glob pattern: %s", pattern)
+		}
+	}
+	return nil
+}
+
+func validateAuthorization(authz config.Authorization) error {
+	switch authz.User {
+	case "", config.AuthorizationUserSender, config.AuthorizationUserPRAuthor:
+	default:
+		return fmt.Errorf(`'user' must be either sender or pr-author: %s`, authz.User)
+	}
+	for _, team := range authz.Teams {
+		if _, _, ok := config.SplitTeam(team); !ok {
+			return fmt.Errorf(`the team must be "<organization>/<team slug>": %s`, team)
+		}
 	}
 	return nil
 }
@@ -153,21 +295,29 @@ Please check.
 
 ` + "```" + `
 {{.Error}}
-` + "```"
+` + "```" + `
+{{- if .Builds}}
+
+The following builds were started.
+{{range .Builds}}
+* {{.ARN}}
+{{- end}}
+{{- end}}
+`
 
 func setErrorNotificationTemplate(cfg *config.Config) error {
 	if !cfg.ErrorNotificationTemplate.Empty() {
 		return nil
 	}
 	if errTpl := os.Getenv("ERROR_NOTIFICATION_TEMPLATE"); errTpl != "" {
-		tpl, err := template.New(errTpl)
+		tpl, err := template.NewTrusted(errTpl)
 		if err != nil {
 			return fmt.Errorf("parse ERROR_NOTIFICATION_TEMPLATE as template: %w", err)
 		}
 		cfg.ErrorNotificationTemplate = tpl
 		return nil
 	}
-	tpl, err := template.New(defaultErrorNotificationTemplate)
+	tpl, err := template.NewTrusted(defaultErrorNotificationTemplate)
 	if err != nil {
 		return fmt.Errorf("parse defaultErroNotificationTemplate as template: %w", err)
 	}
@@ -21,9 +21,25 @@ func readSecretFromSSM(ctx context.Context, svc SSM, parameterName config.Parame
 	var err error
 	secret := lambda.Secret{}
 
-	secret.GitHubToken, err = getSecret(ctx, svc, parameterName.GitHubToken)
-	if err != nil {
-		return secret, fmt.Errorf("get GitHub Access Token: %w", err)
+	if parameterName.GitHubToken != "" {
+		secret.GitHubToken, err = getSecret(ctx, svc, parameterName.GitHubToken)
+		if err != nil {
+			return secret, fmt.Errorf("get GitHub Access Token: %w", err)
+		}
+	}
+
+	if parameterName.GitHubAppID != "" {
+		secret.GitHubAppID, err = getSecret(ctx, svc, parameterName.GitHubAppID)
+		if err != nil {
+			return secret, fmt.Errorf("get GitHub App ID: %w", err)
+		}
+	}
+
+	if parameterName.GitHubAppPrivateKey != "" {
+		secret.GitHubAppPrivateKey, err = getSecret(ctx, svc, parameterName.GitHubAppPrivateKey)
+		if err != nil {
+			return secret, fmt.Errorf("get GitHub App private key: %w", err)
+		}
 	}
 
 	secret.WebhookSecret, err = getSecret(ctx, svc, parameterName.WebhookSecret)
@@ -0,0 +1,149 @@
+package lambda
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+)
+
+// sensitiveOverrides are security-sensitive overrides of StartBuild and StartBuildBatch.
+type sensitiveOverrides struct {
+	EncryptionKey    *string
+	SourceAuth       *codebuild.SourceAuth
+	SecondarySources []*codebuild.ProjectSource
+	Artifacts        []*codebuild.ProjectArtifacts
+	Cache            *codebuild.ProjectCache
+	LogsConfig       *codebuild.LogsConfig
+	// BuildBatchConfig is set only for batch builds
+	BuildBatchConfig *codebuild.ProjectBuildBatchConfig
+}
+
+// matchAny returns true if s matches any pattern.
+// Patterns are validated when the configuration is read, so errors are ignored.
+func matchAny(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		if f, _ := doublestar.Match(pattern, s); f {
+			return true
+		}
+	}
+	return false
+}
+
+func checkAllowed(patterns []string, name, value string) error {
+	if matchAny(patterns, value) {
+		return nil
+	}
+	return fmt.Errorf("the %s isn't allowed by the hook's allowed-overrides: %s", name, value)
+}
+
+// checkLocation checks the location of the override.
+// If the location is nil, the project's location is used, but other fields of the override are still sensitive,
+// so the override is allowed only if the allowlist isn't empty.
+func checkLocation(patterns []string, name string, location *string) error {
+	if location != nil {
+		return checkAllowed(patterns, name+" location", aws.StringValue(location))
+	}
+	if len(patterns) == 0 {
+		return fmt.Errorf("the %s override isn't allowed by the hook's allowed-overrides", name)
+	}
+	return nil
+}
+
+func checkEncryptionDisabled(allowed config.AllowedOverrides, name string, disabled *bool) error {
+	if aws.BoolValue(disabled) && !allowed.EncryptionDisabled {
+		return fmt.Errorf("encryption-disabled of the %s isn't allowed by the hook's allowed-overrides", name)
+	}
+	return nil
+}
+
+// checkOverrides returns an error if the build input has overrides which aren't allowed.
+func checkOverrides(buildInput *domain.BuildInput, allowed config.AllowedOverrides) error {
+	if buildInput.Batched {
+		input := buildInput.BatchBuild
+		return checkSensitiveOverrides(sensitiveOverrides{
+			EncryptionKey:    input.EncryptionKeyOverride,
+			SourceAuth:       input.SourceAuthOverride,
+			SecondarySources: input.SecondarySourcesOverride,
+			Artifacts:        artifactsOverrides(input.ArtifactsOverride, input.SecondaryArtifactsOverride),
+			Cache:            input.CacheOverride,
+			LogsConfig:       input.LogsConfigOverride,
+			BuildBatchConfig: input.BuildBatchConfigOverride,
+		}, allowed)
+	}
+	for _, input := range buildInput.Builds {
+		if err := checkSensitiveOverrides(sensitiveOverrides{
+			EncryptionKey:    input.EncryptionKeyOverride,
+			SourceAuth:       input.SourceAuthOverride,
+			SecondarySources: input.SecondarySourcesOverride,
+			Artifacts:        artifactsOverrides(input.ArtifactsOverride, input.SecondaryArtifactsOverride),
+			Cache:            input.CacheOverride,
+			LogsConfig:       input.LogsConfigOverride,
+		}, allowed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// artifactsOverrides returns the primary artifacts and secondary artifacts.
+func artifactsOverrides(artifacts *codebuild.ProjectArtifacts, secondaryArtifacts []*codebuild.ProjectArtifacts) []*codebuild.ProjectArtifacts {
+	if artifacts == nil {
+		return secondaryArtifacts
+	}
+	return append([]*codebuild.ProjectArtifacts{artifacts}, secondaryArtifacts...)
+}
+
+func checkSensitiveOverrides(overrides sensitiveOverrides, allowed config.AllowedOverrides) error {
+	if overrides.EncryptionKey != nil {
+		if err := checkAllowed(allowed.EncryptionKeys, "encryption key", aws.StringValue(overrides.EncryptionKey)); err != nil {
+			return err
+		}
+	}
+	if overrides.SourceAuth != nil {
+		if err := checkAllowed(allowed.SourceAuthResources, "source auth resource", aws.StringValue(overrides.SourceAuth.Resource)); err != nil {
+			return err
+		}
+	}
+	for _, src := range overrides.SecondarySources {
+		if err := checkAllowed(allowed.SecondarySourceLocations, "secondary source location", aws.StringValue(src.Location)); err != nil {
+			return err
+		}
+	}
+	for _, artifacts := range overrides.Artifacts {
+		if err := checkLocation(allowed.ArtifactLocations, "artifact", artifacts.Location); err != nil {
+			return err
+		}
+		if err := checkEncryptionDisabled(allowed, "artifacts", artifacts.EncryptionDisabled); err != nil {
+			return err
+		}
+	}
+	if overrides.Cache != nil {
+		if err := checkLocation(allowed.CacheLocations, "cache", overrides.Cache.Location); err != nil {
+			return err
+		}
+	}
+	if logs := overrides.LogsConfig; logs != nil {
+		if logs.CloudWatchLogs != nil {
+			if err := checkLocation(allowed.LogLocations, "log group", logs.CloudWatchLogs.GroupName); err != nil {
+				return err
+			}
+		}
+		if logs.S3Logs != nil {
+			if err := checkLocation(allowed.LogLocations, "log", logs.S3Logs.Location); err != nil {
+				return err
+			}
+			if err := checkEncryptionDisabled(allowed, "S3 logs", logs.S3Logs.EncryptionDisabled); err != nil {
+				return err
+			}
+		}
+	}
+	if overrides.BuildBatchConfig != nil && !allowed.BuildBatchConfig {
+		return errors.New("the build-batch-config override isn't allowed by the hook's allowed-overrides")
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package lambda
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+)
+
+func Test_checkOverrides(t *testing.T) {
+	t.Parallel()
+	allowed := config.AllowedOverrides{
+		EncryptionKeys:           []string{"alias/lambuild-*"},
+		SecondarySourceLocations: []string{"https://github.com/suzuki-shunsuke/**"},
+		CacheLocations:           []string{"lambuild-cache/**"},
+		ArtifactLocations:        []string{"lambuild-artifacts"},
+	}
+	data := []struct {
+		title                   string
+		buildInput              domain.BuildInput
+		allowEncryptionDisabled bool
+		allowBuildBatchConfig   bool
+		isErr                   bool
+	}{
+		{
+			title: "no override",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{{}},
+			},
+		},
+		{
+			title: "allowed",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{
+					{
+						EncryptionKeyOverride: aws.String("alias/lambuild-foo"),
+						SecondarySourcesOverride: []*codebuild.ProjectSource{
+							{Location: aws.String("https://github.com/suzuki-shunsuke/foo")},
+						},
+						CacheOverride: &codebuild.ProjectCache{
+							Location: aws.String("lambuild-cache/foo"),
+						},
+						// artifacts without location are allowed if artifact-locations isn't empty
+						ArtifactsOverride: &codebuild.ProjectArtifacts{
+							Type: aws.String("NO_ARTIFACTS"),
+						},
+					},
+				},
+			},
+		},
+		{
+			title: "encryption key isn't allowed",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{
+					{},
+					{EncryptionKeyOverride: aws.String("alias/foo")},
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "source auth isn't allowed",
+			buildInput: domain.BuildInput{
+				Batched: true,
+				BatchBuild: &codebuild.StartBuildBatchInput{
+					SourceAuthOverride: &codebuild.SourceAuth{
+						Type: aws.String("OAUTH"),
+					},
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "log location isn't allowed",
+			buildInput: domain.BuildInput{
+				Batched: true,
+				BatchBuild: &codebuild.StartBuildBatchInput{
+					LogsConfigOverride: &codebuild.LogsConfig{
+						S3Logs: &codebuild.S3LogsConfig{
+							Location: aws.String("other-bucket/logs"),
+						},
+					},
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "logs without location isn't allowed if log-locations is empty",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{
+					{
+						LogsConfigOverride: &codebuild.LogsConfig{
+							CloudWatchLogs: &codebuild.CloudWatchLogsConfig{
+								Status: aws.String("DISABLED"),
+							},
+						},
+					},
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "secondary artifacts location isn't allowed",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{
+					{
+						SecondaryArtifactsOverride: []*codebuild.ProjectArtifacts{
+							{Location: aws.String("other-bucket")},
+						},
+					},
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "encryption-disabled of artifacts isn't allowed",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{
+					{
+						ArtifactsOverride: &codebuild.ProjectArtifacts{
+							Location:           aws.String("lambuild-artifacts"),
+							EncryptionDisabled: aws.Bool(true),
+						},
+					},
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "encryption-disabled of S3 logs isn't allowed",
+			buildInput: domain.BuildInput{
+				Batched: true,
+				BatchBuild: &codebuild.StartBuildBatchInput{
+					LogsConfigOverride: &codebuild.LogsConfig{
+						S3Logs: &codebuild.S3LogsConfig{
+							Status:             aws.String("ENABLED"),
+							EncryptionDisabled: aws.Bool(true),
+						},
+					},
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "encryption-disabled is allowed",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{
+					{
+						ArtifactsOverride: &codebuild.ProjectArtifacts{
+							Location:           aws.String("lambuild-artifacts"),
+							EncryptionDisabled: aws.Bool(true),
+						},
+					},
+				},
+			},
+			allowEncryptionDisabled: true,
+		},
+		{
+			title: "build-batch-config isn't allowed",
+			buildInput: domain.BuildInput{
+				Batched: true,
+				BatchBuild: &codebuild.StartBuildBatchInput{
+					BuildBatchConfigOverride: &codebuild.ProjectBuildBatchConfig{
+						Restrictions: &codebuild.BatchRestrictions{
+							MaximumBuildsAllowed: aws.Int64(1000),
+						},
+					},
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "build-batch-config is allowed",
+			buildInput: domain.BuildInput{
+				Batched: true,
+				BatchBuild: &codebuild.StartBuildBatchInput{
+					BuildBatchConfigOverride: &codebuild.ProjectBuildBatchConfig{
+						TimeoutInMins: aws.Int64(60),
+					},
+				},
+			},
+			allowBuildBatchConfig: true,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			allowed := allowed
+			allowed.EncryptionDisabled = d.allowEncryptionDisabled
+			allowed.BuildBatchConfig = d.allowBuildBatchConfig
+			err := checkOverrides(&d.buildInput, allowed)
+			if d.isErr {
+				if err == nil {
+					t.Fatal("error should be returned")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
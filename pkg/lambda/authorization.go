@@ -0,0 +1,116 @@
+package lambda
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v37/github"
+	"github.com/sirupsen/logrus"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+)
+
+const defaultDeniedComment = "lambuild doesn't run builds because @%s isn't authorized to run builds in this repository."
+
+// getAuthorizationUser returns the login of the user who is authorized.
+func getAuthorizationUser(data *domain.Data, authz config.Authorization) string {
+	if authz.User == config.AuthorizationUserPRAuthor {
+		if pr, _ := data.PullRequest.PullRequest.Peek(); pr != nil {
+			return pr.GetUser().GetLogin()
+		}
+	}
+	if ev, ok := data.Event.Payload.(interface {
+		GetSender() *github.User
+	}); ok {
+		return ev.GetSender().GetLogin()
+	}
+	return ""
+}
+
+// isAuthorized returns true if the user is in the allowlist, has the permission, or is a member of any team.
+// Conditions are checked in this order to reduce GitHub API calls.
+func isAuthorized(ctx context.Context, data *domain.Data, authz config.Authorization, user string) (bool, error) {
+	if user == "" {
+		return false, nil
+	}
+	for _, u := range authz.Users {
+		// GitHub's login is case insensitive
+		if strings.EqualFold(u, user) {
+			return true, nil
+		}
+	}
+	if !authz.Permission.Empty() {
+		level, err := data.GitHub.GetPermissionLevel(ctx, data.Repository.Owner, data.Repository.Name, user)
+		if err != nil {
+			return false, fmt.Errorf("get the user's permission: %w", err)
+		}
+		if authz.Permission.Satisfied(level) {
+			return true, nil
+		}
+	}
+	for _, team := range authz.Teams {
+		org, slug, ok := config.SplitTeam(team)
+		if !ok {
+			return false, fmt.Errorf("the team is invalid: %s", team)
+		}
+		f, err := data.GitHub.IsTeamMember(ctx, org, slug, user)
+		if err != nil {
+			return false, fmt.Errorf("check whether the user is a member of the team (%s): %w", team, err)
+		}
+		if f {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// authorize returns true if builds can be started.
+// If the user isn't authorized, a comment is sent to the pull request.
+func (handler *Handler) authorize(ctx context.Context, logE *logrus.Entry, data *domain.Data, repo config.Repository) (bool, error) {
+	authz := repo.Authorization
+	if !authz.Enabled() {
+		return true, nil
+	}
+	user := getAuthorizationUser(data, authz)
+	f, err := isAuthorized(ctx, data, authz, user)
+	if err != nil {
+		return false, err
+	}
+	if f {
+		return true, nil
+	}
+	logE = logE.WithField("user", user)
+	logE.Info("the user isn't authorized to run builds")
+	handler.sendDeniedComment(ctx, logE, data, authz, user)
+	return false, nil
+}
+
+// sendDeniedComment sends a comment to the pull request to notify that the user isn't authorized.
+// If the event isn't associated with a pull request, no comment is sent.
+func (handler *Handler) sendDeniedComment(ctx context.Context, logE *logrus.Entry, data *domain.Data, authz config.Authorization, user string) {
+	prNumber, _ := data.PullRequest.Number.Peek()
+	if pr, _ := data.PullRequest.PullRequest.Peek(); pr != nil {
+		prNumber = pr.GetNumber()
+	}
+	if prNumber == 0 {
+		return
+	}
+	cmt := fmt.Sprintf(defaultDeniedComment, user)
+	if !authz.DeniedComment.Empty() {
+		s, err := authz.DeniedComment.Execute(map[string]interface{}{
+			"User":       user,
+			"Repository": data.Repository.FullName,
+		})
+		if err != nil {
+			logE.WithError(err).Error("render a comment to send it to the pull request")
+		} else {
+			cmt = s
+		}
+	}
+	if err := data.GitHub.CreatePRComment(ctx, data.Repository.Owner, data.Repository.Name, prNumber, cmt); err != nil {
+		logE.WithError(err).Error("send a comment to the pull request")
+		return
+	}
+	logE.Info("send a comment to the pull request")
+}
@@ -0,0 +1,136 @@
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v37/github"
+	"github.com/sirupsen/logrus"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+	gh "github.com/suzuki-shunsuke/lambuild/pkg/github"
+)
+
+// authzClient is a GitHub client whose permissions and team memberships are fixed.
+type authzClient struct {
+	*gh.Local
+	permissions map[string]string
+	teams       map[string][]string
+}
+
+func (client *authzClient) GetPermissionLevel(ctx context.Context, owner, repo, user string) (string, error) {
+	if level, ok := client.permissions[user]; ok {
+		return level, nil
+	}
+	return "none", nil
+}
+
+func (client *authzClient) IsTeamMember(ctx context.Context, org, teamSlug, user string) (bool, error) {
+	for _, member := range client.teams[org+"/"+teamSlug] {
+		if member == user {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func mustPermission(t *testing.T, name string) config.Permission {
+	t.Helper()
+	permission, err := config.NewPermission(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return permission
+}
+
+func Test_authorize(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title      string
+		sender     string
+		pr         *github.PullRequest
+		authz      config.Authorization
+		permission string
+		exp        bool
+		expComment string
+	}{
+		{
+			title:  "disabled",
+			sender: "octocat",
+			exp:    true,
+		},
+		{
+			title:  "allowlist",
+			sender: "OctoCat",
+			authz:  config.Authorization{Users: []string{"octocat"}},
+			exp:    true,
+		},
+		{
+			title:  "team",
+			sender: "octocat",
+			authz:  config.Authorization{Teams: []string{"suzuki-shunsuke/maintainers"}},
+			exp:    true,
+		},
+		{
+			title:  "denied",
+			sender: "octocat",
+			pr:     &github.PullRequest{Number: github.Int(5)},
+			authz:  config.Authorization{Users: []string{"foo"}},
+			exp:    false,
+			expComment: "[comment to the pull request #5]\n" +
+				"lambuild doesn't run builds because @octocat isn't authorized to run builds in this repository.\n",
+		},
+		{
+			title:  "pr-author",
+			sender: "octocat",
+			pr: &github.PullRequest{
+				Number: github.Int(5),
+				User:   &github.User{Login: github.String("suzuki-shunsuke")},
+			},
+			authz:      config.Authorization{User: config.AuthorizationUserPRAuthor},
+			permission: "write",
+			exp:        true,
+		},
+		{
+			title:  "push event isn't commented",
+			sender: "octocat",
+			authz:  config.Authorization{Users: []string{"foo"}},
+			exp:    false,
+		},
+	}
+	logE := logrus.NewEntry(logrus.New())
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			authz := d.authz
+			if d.permission != "" {
+				authz.Permission = mustPermission(t, d.permission)
+			}
+			stderr := &bytes.Buffer{}
+			dt := domain.NewData()
+			dt.Repository = domain.Repository{FullName: "suzuki-shunsuke/test-lambuild", Owner: "suzuki-shunsuke", Name: "test-lambuild"}
+			dt.Event.Payload = &github.PullRequestEvent{Sender: &github.User{Login: github.String(d.sender)}}
+			dt.GitHub = &authzClient{
+				Local:       &gh.Local{Stderr: stderr},
+				permissions: map[string]string{"suzuki-shunsuke": "admin"},
+				teams:       map[string][]string{"suzuki-shunsuke/maintainers": {"octocat"}},
+			}
+			if d.pr != nil {
+				dt.PullRequest.PullRequest.Set(d.pr)
+			}
+			handler := &Handler{}
+			f, err := handler.authorize(context.Background(), logE, &dt, config.Repository{Authorization: authz})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f != d.exp {
+				t.Fatalf("got %v, wanted %v", f, d.exp)
+			}
+			if cmt := stderr.String(); cmt != d.expComment {
+				t.Fatalf("comment: got %q, wanted %q", cmt, d.expComment)
+			}
+		})
+	}
+}
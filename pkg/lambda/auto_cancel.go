@@ -0,0 +1,236 @@
+package lambda
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+	"github.com/sirupsen/logrus"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+)
+
+// autoCancelEnvName is the environment variable to find builds which are superseded by new builds.
+// CodeBuild's StartBuild API doesn't support tags, so lambuild tracks builds with the environment variable.
+const autoCancelEnvName = "LAMBUILD_AUTO_CANCEL_KEY"
+
+// maxAutoCancelCandidates is the page size of listing builds which are checked whether they should be stopped.
+const maxAutoCancelCandidates = 100
+
+// maxAutoCancelPages is the maximum number of pages of listing builds, to bound the number of API calls.
+const maxAutoCancelPages = 50
+
+// maxBuildLifetime is the maximum build timeout (36 hours) plus the maximum queued timeout (8 hours).
+// Builds which started before maxBuildLifetime ago can't be in progress, so older builds aren't listed.
+const maxBuildLifetime = 44 * time.Hour
+
+// isAutoCancelEnabled returns true if auto-cancel is enabled.
+// hook's auto-cancel takes precedence over repo's auto-cancel.
+func isAutoCancelEnabled(repo config.Repository, hook config.Hook) bool {
+	if hook.AutoCancel != nil {
+		return *hook.AutoCancel
+	}
+	return repo.AutoCancel
+}
+
+// getAutoCancelKey returns the key to find builds of the same pull request or branch.
+// If auto-cancel is disabled or the key can't be determined without GitHub API, an empty string is returned.
+func getAutoCancelKey(data *domain.Data, repo config.Repository, hook config.Hook) string {
+	if !isAutoCancelEnabled(repo, hook) {
+		return ""
+	}
+	if data.Event.Headers.Event == "push" {
+		return data.Repository.FullName + ":push:" + data.Ref
+	}
+	number, _ := data.PullRequest.Number.Peek()
+	if number == 0 {
+		if pr, _ := data.PullRequest.PullRequest.Peek(); pr != nil {
+			number = pr.GetNumber()
+		}
+	}
+	if number == 0 {
+		return ""
+	}
+	return data.Repository.FullName + ":pull_request:" + strconv.Itoa(number)
+}
+
+// setAutoCancelKey sets the auto-cancel key to builds' environment variables.
+func setAutoCancelKey(buildInput *domain.BuildInput, key string) {
+	if key == "" {
+		return
+	}
+	env := &codebuild.EnvironmentVariable{
+		Name:  aws.String(autoCancelEnvName),
+		Type:  aws.String(codebuild.EnvironmentVariableTypePlaintext),
+		Value: aws.String(key),
+	}
+	if buildInput.Batched {
+		buildInput.BatchBuild.EnvironmentVariablesOverride = setEnvVar(buildInput.BatchBuild.EnvironmentVariablesOverride, env)
+		return
+	}
+	for _, build := range buildInput.Builds {
+		build.EnvironmentVariablesOverride = setEnvVar(build.EnvironmentVariablesOverride, env)
+	}
+}
+
+// setEnvVar sets the environment variable.
+// If the environment variable has already been defined, it is overwritten so that the name isn't duplicated.
+func setEnvVar(envVars []*codebuild.EnvironmentVariable, env *codebuild.EnvironmentVariable) []*codebuild.EnvironmentVariable {
+	for i, e := range envVars {
+		if aws.StringValue(e.Name) == aws.StringValue(env.Name) {
+			envVars[i] = env
+			return envVars
+		}
+	}
+	return append(envVars, env)
+}
+
+// isSuperseded returns true if the build is in progress and is started for the same pull request or branch but the other commit.
+func isSuperseded(env *codebuild.ProjectEnvironment, sourceVersion, status, key, sha string) bool {
+	if status != codebuild.StatusTypeInProgress || sourceVersion == sha {
+		return false
+	}
+	if env == nil {
+		return false
+	}
+	for _, e := range env.EnvironmentVariables {
+		if aws.StringValue(e.Name) == autoCancelEnvName {
+			return aws.StringValue(e.Value) == key
+		}
+	}
+	return false
+}
+
+// cancelSupersededBuilds stops in progress builds and batch builds which are superseded by the new commit.
+func (handler *Handler) cancelSupersededBuilds(ctx context.Context, logE *logrus.Entry, data *domain.Data, repo config.Repository, hook config.Hook) error {
+	key := getAutoCancelKey(data, repo, hook)
+	if key == "" {
+		return nil
+	}
+	logE = logE.WithField("auto_cancel_key", key)
+	cb := handler.getCodeBuild(repo, hook)
+	projectName := getProjectName(repo, hook)
+	if err := cancelBuildBatches(ctx, logE, cb, projectName, key, data.SHA); err != nil {
+		return err
+	}
+	return cancelSingleBuilds(ctx, logE, cb, projectName, key, data.SHA, time.Now())
+}
+
+func cancelBuildBatches(ctx context.Context, logE *logrus.Entry, cb CodeBuild, projectName, key, sha string) error {
+	var nextToken *string
+	for page := 0; page < maxAutoCancelPages; page++ {
+		listOut, err := cb.ListBuildBatchesForProjectWithContext(ctx, &codebuild.ListBuildBatchesForProjectInput{
+			ProjectName: aws.String(projectName),
+			SortOrder:   aws.String(codebuild.SortOrderTypeDescending),
+			MaxResults:  aws.Int64(maxAutoCancelCandidates),
+			NextToken:   nextToken,
+			Filter: &codebuild.BuildBatchFilter{
+				Status: aws.String(codebuild.StatusTypeInProgress),
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("list batch builds: %w", err)
+		}
+		if len(listOut.Ids) == 0 {
+			return nil
+		}
+		getOut, err := cb.BatchGetBuildBatchesWithContext(ctx, &codebuild.BatchGetBuildBatchesInput{
+			Ids: listOut.Ids,
+		})
+		if err != nil {
+			return fmt.Errorf("get batch builds: %w", err)
+		}
+		for _, batch := range getOut.BuildBatches {
+			if !isSuperseded(batch.Environment, aws.StringValue(batch.SourceVersion), aws.StringValue(batch.BuildBatchStatus), key, sha) {
+				continue
+			}
+			if _, err := cb.StopBuildBatchWithContext(ctx, &codebuild.StopBuildBatchInput{
+				Id: batch.Id,
+			}); err != nil {
+				logE.WithError(err).Error("stop a batch build")
+				return fmt.Errorf("stop a batch build (%s): %w", aws.StringValue(batch.Id), err)
+			}
+			logE.WithFields(logrus.Fields{
+				"build_arn": aws.StringValue(batch.Arn),
+			}).Info("stop a superseded batch build")
+		}
+		if listOut.NextToken == nil {
+			return nil
+		}
+		nextToken = listOut.NextToken
+	}
+	logE.Warn("stop finding superseded batch builds because too many batch builds are in progress")
+	return nil
+}
+
+// cancelSingleBuilds stops in progress builds which are superseded by the new commit.
+// ListBuildsForProject doesn't support filtering by the status, so builds are listed from the newest
+// until builds which can't be in progress are found.
+func cancelSingleBuilds(ctx context.Context, logE *logrus.Entry, cb CodeBuild, projectName, key, sha string, now time.Time) error {
+	var nextToken *string
+	for page := 0; page < maxAutoCancelPages; page++ {
+		listOut, err := cb.ListBuildsForProjectWithContext(ctx, &codebuild.ListBuildsForProjectInput{
+			ProjectName: aws.String(projectName),
+			SortOrder:   aws.String(codebuild.SortOrderTypeDescending),
+			NextToken:   nextToken,
+		})
+		if err != nil {
+			return fmt.Errorf("list builds: %w", err)
+		}
+		if len(listOut.Ids) == 0 {
+			return nil
+		}
+		getOut, err := cb.BatchGetBuildsWithContext(ctx, &codebuild.BatchGetBuildsInput{
+			Ids: listOut.Ids,
+		})
+		if err != nil {
+			return fmt.Errorf("get builds: %w", err)
+		}
+		if err := stopSupersededBuilds(ctx, logE, cb, getOut.Builds, key, sha); err != nil {
+			return err
+		}
+		if listOut.NextToken == nil || hasExpiredBuild(getOut.Builds, now) {
+			return nil
+		}
+		nextToken = listOut.NextToken
+	}
+	logE.Warn("stop finding superseded builds because too many builds are found")
+	return nil
+}
+
+// hasExpiredBuild returns true if any build started before maxBuildLifetime ago.
+// Builds are sorted from the newest, so builds of the following pages are older.
+func hasExpiredBuild(builds []*codebuild.Build, now time.Time) bool {
+	deadline := now.Add(-maxBuildLifetime)
+	for _, build := range builds {
+		if build.StartTime != nil && build.StartTime.Before(deadline) {
+			return true
+		}
+	}
+	return false
+}
+
+func stopSupersededBuilds(ctx context.Context, logE *logrus.Entry, cb CodeBuild, builds []*codebuild.Build, key, sha string) error {
+	for _, build := range builds {
+		if build.BuildBatchArn != nil {
+			// builds in batch builds are stopped by StopBuildBatch
+			continue
+		}
+		if !isSuperseded(build.Environment, aws.StringValue(build.SourceVersion), aws.StringValue(build.BuildStatus), key, sha) {
+			continue
+		}
+		if _, err := cb.StopBuildWithContext(ctx, &codebuild.StopBuildInput{
+			Id: build.Id,
+		}); err != nil {
+			logE.WithError(err).Error("stop a build")
+			return fmt.Errorf("stop a build (%s): %w", aws.StringValue(build.Id), err)
+		}
+		logE.WithFields(logrus.Fields{
+			"build_arn": aws.StringValue(build.Arn),
+		}).Info("stop a superseded build")
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package lambda
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v37/github"
+	"github.com/sirupsen/logrus"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+)
+
+func Test_getAutoCancelKey(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title string
+		event string
+		ref   string
+		pr    *github.PullRequest
+		repo  config.Repository
+		hook  config.Hook
+		exp   string
+	}{
+		{
+			title: "disabled",
+			event: "push",
+			ref:   "refs/heads/main",
+		},
+		{
+			title: "push",
+			event: "push",
+			ref:   "refs/heads/main",
+			repo:  config.Repository{AutoCancel: true},
+			exp:   "suzuki-shunsuke/test-lambuild:push:refs/heads/main",
+		},
+		{
+			title: "pull_request",
+			event: "pull_request",
+			pr:    &github.PullRequest{Number: github.Int(5)},
+			hook:  config.Hook{AutoCancel: aws.Bool(true)},
+			exp:   "suzuki-shunsuke/test-lambuild:pull_request:5",
+		},
+		{
+			title: "hook overrides repo",
+			event: "push",
+			ref:   "refs/heads/main",
+			repo:  config.Repository{AutoCancel: true},
+			hook:  config.Hook{AutoCancel: aws.Bool(false)},
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			dt := domain.NewData()
+			dt.Event.Headers.Event = d.event
+			dt.Ref = d.ref
+			dt.Repository.FullName = "suzuki-shunsuke/test-lambuild"
+			if d.pr != nil {
+				dt.PullRequest.PullRequest.Set(d.pr)
+			}
+			if key := getAutoCancelKey(&dt, d.repo, d.hook); key != d.exp {
+				t.Fatalf("wanted %s, got %s", d.exp, key)
+			}
+		})
+	}
+}
+
+func Test_isSuperseded(t *testing.T) {
+	t.Parallel()
+	env := &codebuild.ProjectEnvironment{
+		EnvironmentVariables: []*codebuild.EnvironmentVariable{
+			{
+				Name:  aws.String(autoCancelEnvName),
+				Value: aws.String("key"),
+			},
+		},
+	}
+	data := []struct {
+		title         string
+		env           *codebuild.ProjectEnvironment
+		sourceVersion string
+		status        string
+		exp           bool
+	}{
+		{
+			title:         "superseded",
+			env:           env,
+			sourceVersion: "old",
+			status:        codebuild.StatusTypeInProgress,
+			exp:           true,
+		},
+		{
+			title:         "same commit",
+			env:           env,
+			sourceVersion: "new",
+			status:        codebuild.StatusTypeInProgress,
+		},
+		{
+			title:         "completed",
+			env:           env,
+			sourceVersion: "old",
+			status:        codebuild.StatusTypeSucceeded,
+		},
+		{
+			title:         "not started by lambuild",
+			env:           &codebuild.ProjectEnvironment{},
+			sourceVersion: "old",
+			status:        codebuild.StatusTypeInProgress,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			if f := isSuperseded(d.env, d.sourceVersion, d.status, "key", "new"); f != d.exp {
+				t.Fatalf("wanted %v, got %v", d.exp, f)
+			}
+		})
+	}
+}
+
+// pagedCodeBuild returns builds page by page.
+type pagedCodeBuild struct {
+	CodeBuild
+	pages   [][]*codebuild.Build
+	listed  int
+	stopped []string
+}
+
+func (cb *pagedCodeBuild) ListBuildsForProjectWithContext(ctx aws.Context, input *codebuild.ListBuildsForProjectInput, opts ...request.Option) (*codebuild.ListBuildsForProjectOutput, error) {
+	page := 0
+	if input.NextToken != nil {
+		fmt.Sscanf(aws.StringValue(input.NextToken), "%d", &page) //nolint:errcheck
+	}
+	cb.listed++
+	out := &codebuild.ListBuildsForProjectOutput{}
+	for _, build := range cb.pages[page] {
+		out.Ids = append(out.Ids, build.Id)
+	}
+	if page+1 < len(cb.pages) {
+		out.NextToken = aws.String(fmt.Sprint(page + 1))
+	}
+	return out, nil
+}
+
+func (cb *pagedCodeBuild) BatchGetBuildsWithContext(ctx aws.Context, input *codebuild.BatchGetBuildsInput, opts ...request.Option) (*codebuild.BatchGetBuildsOutput, error) {
+	out := &codebuild.BatchGetBuildsOutput{}
+	for _, page := range cb.pages {
+		for _, build := range page {
+			for _, id := range input.Ids {
+				if aws.StringValue(id) == aws.StringValue(build.Id) {
+					out.Builds = append(out.Builds, build)
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+func (cb *pagedCodeBuild) StopBuildWithContext(ctx aws.Context, input *codebuild.StopBuildInput, opts ...request.Option) (*codebuild.StopBuildOutput, error) {
+	cb.stopped = append(cb.stopped, aws.StringValue(input.Id))
+	return &codebuild.StopBuildOutput{}, nil
+}
+
+func Test_cancelSingleBuilds(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	env := &codebuild.ProjectEnvironment{
+		EnvironmentVariables: []*codebuild.EnvironmentVariable{
+			{Name: aws.String(autoCancelEnvName), Value: aws.String("key")},
+		},
+	}
+	newBuild := func(id, status string, startTime time.Time) *codebuild.Build {
+		return &codebuild.Build{
+			Id:            aws.String(id),
+			BuildStatus:   aws.String(status),
+			SourceVersion: aws.String("old"),
+			Environment:   env,
+			StartTime:     aws.Time(startTime),
+		}
+	}
+	data := []struct {
+		title   string
+		pages   [][]*codebuild.Build
+		stopped []string
+		listed  int
+	}{
+		{
+			title: "in progress build on the second page",
+			pages: [][]*codebuild.Build{
+				{
+					newBuild("finished", codebuild.StatusTypeSucceeded, now.Add(-time.Hour)),
+					{
+						Id:            aws.String("batch"),
+						BuildStatus:   aws.String(codebuild.StatusTypeInProgress),
+						BuildBatchArn: aws.String("batch"),
+						Environment:   env,
+					},
+				},
+				{
+					newBuild("running", codebuild.StatusTypeInProgress, now.Add(-2*time.Hour)),
+				},
+			},
+			stopped: []string{"running"},
+			listed:  2,
+		},
+		{
+			title: "older builds aren't listed",
+			pages: [][]*codebuild.Build{
+				{
+					newBuild("expired", codebuild.StatusTypeSucceeded, now.Add(-maxBuildLifetime-time.Hour)),
+				},
+				{
+					newBuild("running", codebuild.StatusTypeInProgress, now.Add(-maxBuildLifetime-2*time.Hour)),
+				},
+			},
+			listed: 1,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			cb := &pagedCodeBuild{pages: d.pages}
+			if err := cancelSingleBuilds(context.Background(), logrus.NewEntry(logrus.New()), cb, "project", "key", "new", now); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(d.stopped, cb.stopped); diff != "" {
+				t.Fatal(diff)
+			}
+			if cb.listed != d.listed {
+				t.Fatalf("builds should be listed %d times, but listed %d times", d.listed, cb.listed)
+			}
+		})
+	}
+}
+
+func Test_setAutoCancelKey(t *testing.T) {
+	t.Parallel()
+	buildInput := domain.BuildInput{
+		Builds: []*codebuild.StartBuildInput{
+			{
+				EnvironmentVariablesOverride: []*codebuild.EnvironmentVariable{
+					{Name: aws.String("FOO"), Value: aws.String("foo")},
+					{Name: aws.String(autoCancelEnvName), Value: aws.String("defined in buildspec")},
+				},
+			},
+		},
+	}
+	setAutoCancelKey(&buildInput, "key")
+	envVars := buildInput.Builds[0].EnvironmentVariablesOverride
+	if len(envVars) != 2 { //nolint:gomnd
+		t.Fatalf("environment variables shouldn't be duplicated: %d", len(envVars))
+	}
+	if v := aws.StringValue(envVars[1].Value); v != "key" {
+		t.Fatalf(`got %s, wanted "key"`, v)
+	}
+}
@@ -0,0 +1,182 @@
+package lambda
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+	"gopkg.in/yaml.v2"
+)
+
+// buildEnvironment is a build environment which is checked by the build policy.
+type buildEnvironment struct {
+	Image           string
+	ComputeType     string `yaml:"compute-type"`
+	Type            string
+	PrivilegedMode  bool     `yaml:"privileged-mode"`
+	ComputeTypes    []string `yaml:"-"`
+	Images          []string `yaml:"-"`
+	EnvironmentName string   `yaml:"-"`
+}
+
+// batchElement is an element of build-graph and build-list in the rendered buildspec.
+type batchElement struct {
+	Identifier string
+	Env        buildEnvironment
+}
+
+// renderedBatch is the batch of the rendered buildspec.
+type renderedBatch struct {
+	Batch struct {
+		BuildGraph  []batchElement `yaml:"build-graph"`
+		BuildList   []batchElement `yaml:"build-list"`
+		BuildMatrix struct {
+			Static struct {
+				Env buildEnvironment
+			}
+			Dynamic struct {
+				Env struct {
+					ComputeType []string `yaml:"compute-type"`
+					Image       []string
+				}
+			}
+		} `yaml:"build-matrix"`
+	}
+}
+
+// getBuildPolicy returns the build policy.
+// hook's policy takes precedence over repo's policy.
+func getBuildPolicy(repo config.Repository, hook config.Hook) config.BuildPolicy {
+	return repo.BuildPolicy.Merge(hook.BuildPolicy)
+}
+
+// checkBuildPolicy returns an error if the build input violates the build policy.
+// Single builds, the batch build, and every element of the batch build are checked.
+func checkBuildPolicy(buildInput *domain.BuildInput, policy config.BuildPolicy) error {
+	if buildInput.Batched {
+		input := buildInput.BatchBuild
+		if err := checkBuildEnvironment(policy, buildEnvironment{
+			Image:          aws.StringValue(input.ImageOverride),
+			ComputeType:    aws.StringValue(input.ComputeTypeOverride),
+			Type:           aws.StringValue(input.EnvironmentTypeOverride),
+			PrivilegedMode: aws.BoolValue(input.PrivilegedModeOverride),
+		}); err != nil {
+			return err
+		}
+		if batchConfig := input.BuildBatchConfigOverride; batchConfig != nil {
+			if err := checkBatchRestrictions(policy, batchConfig.Restrictions); err != nil {
+				return err
+			}
+		}
+		return checkBatchBuildspec(aws.StringValue(input.BuildspecOverride), policy)
+	}
+	for _, input := range buildInput.Builds {
+		if err := checkBuildEnvironment(policy, buildEnvironment{
+			Image:          aws.StringValue(input.ImageOverride),
+			ComputeType:    aws.StringValue(input.ComputeTypeOverride),
+			Type:           aws.StringValue(input.EnvironmentTypeOverride),
+			PrivilegedMode: aws.BoolValue(input.PrivilegedModeOverride),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBatchRestrictions returns an error if restrictions of build-batch-config violate the policy.
+// If the policy has maximum-builds-allowed, build-batch-config must have maximum-builds-allowed
+// because build-batch-config without it replaces the project's restrictions.
+func checkBatchRestrictions(policy config.BuildPolicy, restrictions *codebuild.BatchRestrictions) error {
+	if policy.MaximumBuildsAllowed > 0 {
+		if restrictions == nil || restrictions.MaximumBuildsAllowed == nil {
+			return fmt.Errorf("build-batch-config.restrictions.maximum-builds-allowed is required by the build policy (maximum: %d)", policy.MaximumBuildsAllowed)
+		}
+		if n := aws.Int64Value(restrictions.MaximumBuildsAllowed); n > policy.MaximumBuildsAllowed {
+			return fmt.Errorf("build-batch-config.restrictions.maximum-builds-allowed exceeds the build policy: %d (maximum: %d)", n, policy.MaximumBuildsAllowed)
+		}
+	}
+	if restrictions == nil {
+		return nil
+	}
+	return checkBuildEnvironment(policy, buildEnvironment{
+		EnvironmentName: "build-batch-config.restrictions",
+		ComputeTypes:    aws.StringValueSlice(restrictions.ComputeTypesAllowed),
+	})
+}
+
+func checkBatchBuildspec(buildspec string, policy config.BuildPolicy) error {
+	rendered := renderedBatch{}
+	if err := yaml.Unmarshal([]byte(buildspec), &rendered); err != nil {
+		return fmt.Errorf("parse the rendered buildspec: %w", err)
+	}
+	batch := rendered.Batch
+	for _, elems := range [][]batchElement{batch.BuildGraph, batch.BuildList} {
+		for _, elem := range elems {
+			env := elem.Env
+			env.EnvironmentName = elem.Identifier
+			if err := checkBuildEnvironment(policy, env); err != nil {
+				return err
+			}
+		}
+	}
+	static := batch.BuildMatrix.Static.Env
+	static.EnvironmentName = "build-matrix.static"
+	if err := checkBuildEnvironment(policy, static); err != nil {
+		return err
+	}
+	return checkBuildEnvironment(policy, buildEnvironment{
+		EnvironmentName: "build-matrix.dynamic",
+		Images:          batch.BuildMatrix.Dynamic.Env.Image,
+		ComputeTypes:    batch.BuildMatrix.Dynamic.Env.ComputeType,
+	})
+}
+
+// checkBuildEnvironment returns an error if env violates the policy.
+// Empty values aren't checked because the project's values are used.
+func checkBuildEnvironment(policy config.BuildPolicy, env buildEnvironment) error {
+	prefix := ""
+	if env.EnvironmentName != "" {
+		prefix = env.EnvironmentName + ": "
+	}
+	images := env.Images
+	if env.Image != "" {
+		images = append(images, env.Image)
+	}
+	for _, image := range images {
+		if len(policy.Images) != 0 && !matchAny(policy.Images, image) {
+			return fmt.Errorf("%sthe image isn't allowed by the build policy: %s (allowed images: %s)", prefix, image, strings.Join(policy.Images, ", "))
+		}
+	}
+	computeTypes := env.ComputeTypes
+	if env.ComputeType != "" {
+		computeTypes = append(computeTypes, env.ComputeType)
+	}
+	for _, computeType := range computeTypes {
+		if !inAllowlist(policy.ComputeTypes, computeType) {
+			return fmt.Errorf("%sthe compute type isn't allowed by the build policy: %s (allowed compute types: %s)", prefix, computeType, strings.Join(policy.ComputeTypes, ", "))
+		}
+	}
+	if env.Type != "" && !inAllowlist(policy.EnvironmentTypes, env.Type) {
+		return fmt.Errorf("%sthe environment type isn't allowed by the build policy: %s (allowed environment types: %s)", prefix, env.Type, strings.Join(policy.EnvironmentTypes, ", "))
+	}
+	if env.PrivilegedMode && !policy.PrivilegedModeAllowed() {
+		return fmt.Errorf("%sprivileged-mode isn't allowed by the build policy", prefix)
+	}
+	return nil
+}
+
+// inAllowlist returns true if the allowlist is empty or contains s.
+func inAllowlist(list []string, s string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, a := range list {
+		if a == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package lambda
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+)
+
+func Test_checkBuildPolicy(t *testing.T) {
+	t.Parallel()
+	policy := config.BuildPolicy{
+		Images:               []string{"aws/codebuild/standard:*"},
+		ComputeTypes:         []string{"BUILD_GENERAL1_SMALL", "BUILD_GENERAL1_MEDIUM"},
+		AllowPrivilegedMode:  aws.Bool(false),
+		MaximumBuildsAllowed: 10,
+	}
+	data := []struct {
+		title      string
+		buildInput domain.BuildInput
+		isErr      bool
+	}{
+		{
+			title: "project's environment",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{{}},
+			},
+		},
+		{
+			title: "allowed",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{
+					{
+						ImageOverride:           aws.String("aws/codebuild/standard:5.0"),
+						ComputeTypeOverride:     aws.String("BUILD_GENERAL1_SMALL"),
+						EnvironmentTypeOverride: aws.String("LINUX_CONTAINER"),
+						PrivilegedModeOverride:  aws.Bool(false),
+					},
+				},
+			},
+		},
+		{
+			title: "item's image isn't allowed",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{
+					{},
+					{ImageOverride: aws.String("alpine:3.13.0")},
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "privileged mode isn't allowed",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{
+					{PrivilegedModeOverride: aws.Bool(true)},
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "batch build",
+			buildInput: domain.BuildInput{
+				Batched: true,
+				BatchBuild: &codebuild.StartBuildBatchInput{
+					BuildspecOverride: aws.String(`
+batch:
+  build-list:
+  - identifier: foo
+    env:
+      compute-type: BUILD_GENERAL1_MEDIUM
+  build-matrix:
+    dynamic:
+      env:
+        image:
+        - aws/codebuild/standard:4.0
+        - aws/codebuild/standard:5.0
+`),
+				},
+			},
+		},
+		{
+			title: "batch element's compute type isn't allowed",
+			buildInput: domain.BuildInput{
+				Batched: true,
+				BatchBuild: &codebuild.StartBuildBatchInput{
+					BuildspecOverride: aws.String(`
+batch:
+  build-graph:
+  - identifier: foo
+  - identifier: bar
+    env:
+      compute-type: BUILD_GENERAL1_2XLARGE
+`),
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "batch element's privileged mode isn't allowed",
+			buildInput: domain.BuildInput{
+				Batched: true,
+				BatchBuild: &codebuild.StartBuildBatchInput{
+					BuildspecOverride: aws.String(`
+batch:
+  build-matrix:
+    static:
+      env:
+        privileged-mode: true
+`),
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "maximum-builds-allowed is allowed",
+			buildInput: domain.BuildInput{
+				Batched: true,
+				BatchBuild: &codebuild.StartBuildBatchInput{
+					BuildBatchConfigOverride: &codebuild.ProjectBuildBatchConfig{
+						Restrictions: &codebuild.BatchRestrictions{
+							ComputeTypesAllowed:  aws.StringSlice([]string{"BUILD_GENERAL1_SMALL"}),
+							MaximumBuildsAllowed: aws.Int64(10),
+						},
+					},
+				},
+			},
+		},
+		{
+			title: "maximum-builds-allowed exceeds the policy",
+			buildInput: domain.BuildInput{
+				Batched: true,
+				BatchBuild: &codebuild.StartBuildBatchInput{
+					BuildBatchConfigOverride: &codebuild.ProjectBuildBatchConfig{
+						Restrictions: &codebuild.BatchRestrictions{
+							MaximumBuildsAllowed: aws.Int64(100),
+						},
+					},
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "build-batch-config without maximum-builds-allowed",
+			buildInput: domain.BuildInput{
+				Batched: true,
+				BatchBuild: &codebuild.StartBuildBatchInput{
+					BuildBatchConfigOverride: &codebuild.ProjectBuildBatchConfig{
+						TimeoutInMins: aws.Int64(60),
+					},
+				},
+			},
+			isErr: true,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			err := checkBuildPolicy(&d.buildInput, policy)
+			if d.isErr {
+				if err == nil {
+					t.Fatal("error should be returned")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func Test_getBuildPolicy(t *testing.T) {
+	t.Parallel()
+	repo := config.Repository{
+		BuildPolicy: config.BuildPolicy{
+			Images:       []string{"aws/codebuild/*"},
+			ComputeTypes: []string{"BUILD_GENERAL1_SMALL"},
+		},
+	}
+	hook := config.Hook{
+		BuildPolicy: config.BuildPolicy{
+			ComputeTypes:        []string{"BUILD_GENERAL1_LARGE"},
+			AllowPrivilegedMode: aws.Bool(false),
+		},
+	}
+	policy := getBuildPolicy(repo, hook)
+	if len(policy.Images) != 1 || policy.Images[0] != "aws/codebuild/*" {
+		t.Fatalf("repository's images should be used: %v", policy.Images)
+	}
+	if len(policy.ComputeTypes) != 1 || policy.ComputeTypes[0] != "BUILD_GENERAL1_LARGE" {
+		t.Fatalf("hook's compute types should be used: %v", policy.ComputeTypes)
+	}
+	if policy.PrivilegedModeAllowed() {
+		t.Fatal("privileged mode shouldn't be allowed")
+	}
+}
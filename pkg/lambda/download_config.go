@@ -10,17 +10,27 @@ import (
 	bspec "github.com/suzuki-shunsuke/lambuild/pkg/buildspec"
 	"github.com/suzuki-shunsuke/lambuild/pkg/config"
 	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+	"github.com/suzuki-shunsuke/lambuild/pkg/validator"
 	"gopkg.in/yaml.v2"
 )
 
-// getConfigFromRepo gets the configuration file from the target repository
-func (handler *Handler) getConfigFromRepo(ctx context.Context, logE *logrus.Entry, data *domain.Data, hook config.Hook) ([]bspec.Buildspec, error) {
+// buildspecFile is a configuration file in the target repository.
+// If the file is invalid, Err is set.
+type buildspecFile struct {
+	Path      string
+	Buildspec bspec.Buildspec
+	Err       error
+}
+
+// getConfigFromRepo gets the configuration file at the ref from the target repository.
+// Even if some files are invalid, other files are returned. Errors of invalid files are set to buildspecFile.Err.
+func (handler *Handler) getConfigFromRepo(ctx context.Context, logE *logrus.Entry, data *domain.Data, hook config.Hook, ref string) ([]buildspecFile, error) {
 	// get the configuration file from the target repository
 	if hook.Config == "" {
 		// set the default value
 		hook.Config = "lambuild.yaml"
 	}
-	file, files, err := handler.GitHub.GetContents(ctx, data.Repository.Owner, data.Repository.Name, hook.Config, data.Ref)
+	file, files, err := data.GitHub.GetContents(ctx, data.Repository.Owner, data.Repository.Name, hook.Config, ref)
 	if err != nil {
 		logE.WithFields(logrus.Fields{
 			"path": hook.Config,
@@ -30,40 +40,59 @@ func (handler *Handler) getConfigFromRepo(ctx context.Context, logE *logrus.Entr
 	if file != nil {
 		files = []*github.RepositoryContent{file}
 	}
-	specs := make([]bspec.Buildspec, len(files))
-	for i, file := range files {
+	specs := make([]buildspecFile, 0, len(files))
+	for _, file := range files {
 		filePath := file.GetPath()
 		ext := filepath.Ext(filePath)
 		if ext != ".yaml" && ext != ".yml" {
 			continue
 		}
-		content, err := file.GetContent()
+		buildspec, err := getBuildspec(ctx, logE, data, file, ref)
+		specs = append(specs, buildspecFile{
+			Path:      filePath,
+			Buildspec: buildspec,
+			Err:       err,
+		})
+	}
+	return specs, nil
+}
+
+// getBuildspec gets the content of the configuration file and parses it.
+func getBuildspec(ctx context.Context, logE *logrus.Entry, data *domain.Data, file *github.RepositoryContent, ref string) (bspec.Buildspec, error) {
+	filePath := file.GetPath()
+	content, err := file.GetContent()
+	if err != nil {
+		return bspec.Buildspec{}, fmt.Errorf("get a content: %w", err)
+	}
+	if content == "" {
+		f, _, err := data.GitHub.GetContents(ctx, data.Repository.Owner, data.Repository.Name, filePath, ref)
 		if err != nil {
-			return nil, fmt.Errorf("get a content: %w", err)
+			logE.WithFields(logrus.Fields{
+				"path": filePath,
+			}).WithError(err).Error("get a configuration file by GitHub API")
+			return bspec.Buildspec{}, fmt.Errorf("get a configuration file by GitHub API: %w", err)
 		}
-		if content == "" {
-			f, _, err := handler.GitHub.GetContents(ctx, data.Repository.Owner, data.Repository.Name, filePath, data.Ref)
-			if err != nil {
-				logE.WithFields(logrus.Fields{
-					"path": filePath,
-				}).WithError(err).Error("get a configuration file by GitHub API")
-				return nil, fmt.Errorf("get a configuration file by GitHub API: %w", err)
-			}
-			cnt, err := f.GetContent()
-			if err != nil {
-				return nil, fmt.Errorf("get a content: %w", err)
-			}
-			if cnt == "" {
-				return nil, fmt.Errorf("a content is empty (%s)", filePath)
-			}
-			content = cnt
+		cnt, err := f.GetContent()
+		if err != nil {
+			return bspec.Buildspec{}, fmt.Errorf("get a content: %w", err)
 		}
-
-		buildspec := bspec.Buildspec{}
-		if err := yaml.Unmarshal([]byte(content), &buildspec); err != nil {
-			return nil, fmt.Errorf("unmarshal a buildspec: %w", err)
+		if cnt == "" {
+			return bspec.Buildspec{}, fmt.Errorf("a content is empty (%s)", filePath)
 		}
-		specs[i] = buildspec
+		content = cnt
 	}
-	return specs, nil
+
+	issues, err := validator.Validate(filePath, []byte(content))
+	if err != nil {
+		return bspec.Buildspec{}, fmt.Errorf("validate a buildspec: %w", err)
+	}
+	if len(issues) != 0 {
+		return bspec.Buildspec{}, &validator.Error{Issues: issues}
+	}
+
+	buildspec := bspec.Buildspec{}
+	if err := yaml.Unmarshal([]byte(content), &buildspec); err != nil {
+		return bspec.Buildspec{}, fmt.Errorf("unmarshal a buildspec (%s): %w", filePath, err)
+	}
+	return buildspec, nil
 }
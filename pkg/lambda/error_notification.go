@@ -4,12 +4,14 @@ import (
 	"context"
 
 	"github.com/sirupsen/logrus"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
 )
 
 // sendErrorNotificaiton sends a comment to GitHub PullRequest or commit to notify an error.
 // If prNumber isn't zero a comment is sent to the pull reqquest.
 // If prNumber is zero, which means the event isn't associated with any pull request, a comment is sent to a comment.
-func (handler *Handler) sendErrorNotificaiton(ctx context.Context, e error, repoOwner, repoName string, prNumber int, sha string) {
+// results are passed to the template so that the comment can include builds which were started and errors of each configuration file.
+func (handler *Handler) sendErrorNotificaiton(ctx context.Context, ghClient domain.GitHub, e error, results []Result, repoOwner, repoName string, prNumber int, sha string) {
 	logE := logrus.WithFields(logrus.Fields{
 		"original_error": e,
 		"repo_owner":     repoOwner,
@@ -21,7 +23,9 @@ func (handler *Handler) sendErrorNotificaiton(ctx context.Context, e error, repo
 	// generate a comment
 	var cmt string
 	s, renderErr := handler.Config.ErrorNotificationTemplate.Execute(map[string]interface{}{
-		"Error": e,
+		"Error":   e,
+		"Results": results,
+		"Builds":  resultBuilds(results),
 	})
 	if renderErr != nil {
 		logE.WithError(renderErr).Error("render a comment to send it to the pull request")
@@ -32,7 +36,7 @@ func (handler *Handler) sendErrorNotificaiton(ctx context.Context, e error, repo
 
 	if prNumber == 0 {
 		// send a comment to commit
-		if cmtErr := handler.GitHub.CreateCommitComment(ctx, repoOwner, repoName, sha, cmt); cmtErr != nil {
+		if cmtErr := ghClient.CreateCommitComment(ctx, repoOwner, repoName, sha, cmt); cmtErr != nil {
 			logE.WithError(cmtErr).Error("send a comment to the commit")
 		}
 		logE.Info("send a comment to the commit")
@@ -40,7 +44,7 @@ func (handler *Handler) sendErrorNotificaiton(ctx context.Context, e error, repo
 	}
 
 	// send a comment to pull request
-	if cmtErr := handler.GitHub.CreatePRComment(ctx, repoOwner, repoName, prNumber, cmt); cmtErr != nil {
+	if cmtErr := ghClient.CreatePRComment(ctx, repoOwner, repoName, prNumber, cmt); cmtErr != nil {
 		logE.WithError(cmtErr).Error("send a comment to the pull request")
 	}
 	logE.Info("send a comment to the pull request")
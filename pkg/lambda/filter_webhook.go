@@ -1,40 +1,105 @@
 package lambda
 
 import (
+	"fmt"
+	"path"
+	"strings"
+
 	"github.com/suzuki-shunsuke/lambuild/pkg/config"
 	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
 )
 
 // getRepo returns the configuration of given repository name.
+// An exact name takes precedence over a glob pattern, and a glob pattern takes precedence over a regular expression.
+// If multiple patterns of the same kind match, the first one is used.
+// The default configuration of the repository's organization is merged into the configuration.
 // If no configuration is found, the second returned value is false.
-func getRepo(repos []config.Repository, repoName string) (config.Repository, bool) {
+func getRepo(repos []config.Repository, orgs []config.Organization, repoName string) (config.Repository, bool) {
+	repo, f := findRepo(repos, repoName)
+	if !f {
+		return config.Repository{}, false
+	}
+	owner := strings.SplitN(repoName, "/", 2)[0] //nolint:gomnd
+	for _, org := range orgs {
+		if org.Name == owner {
+			return mergeOrganization(repo, org), true
+		}
+	}
+	return repo, true
+}
+
+func findRepo(repos []config.Repository, repoName string) (config.Repository, bool) {
 	for _, repo := range repos {
 		if repo.Name == repoName {
 			return repo, true
 		}
 	}
+	for _, repo := range repos {
+		if repo.Name == "" {
+			continue
+		}
+		// path.Match returns an error only if the pattern is malformed, and patterns are validated when the configuration is read
+		if f, _ := path.Match(repo.Name, repoName); f {
+			return repo, true
+		}
+	}
+	for _, repo := range repos {
+		if repo.Name == "" && repo.NameRegexp.MatchString(repoName) {
+			return repo, true
+		}
+	}
 	return config.Repository{}, false
 }
 
-// matchHook returns true if data matches hook's condition.
+// mergeOrganization sets the organization's CodeBuild settings and hooks to the repository if the repository doesn't have them.
+func mergeOrganization(repo config.Repository, org config.Organization) config.Repository {
+	if repo.CodeBuild.ProjectName == "" {
+		repo.CodeBuild.ProjectName = org.CodeBuild.ProjectName
+	}
+	if repo.CodeBuild.AssumeRoleARN == "" {
+		repo.CodeBuild.AssumeRoleARN = org.CodeBuild.AssumeRoleARN
+	}
+	if len(repo.Hooks) == 0 {
+		repo.Hooks = org.Hooks
+	}
+	repo.BuildPolicy = org.BuildPolicy.Merge(repo.BuildPolicy)
+	return repo
+}
+
+// matchHook returns true if data matches hook's condition and paths filter.
 func matchHook(data *domain.Data, hook config.Hook) (bool, error) {
-	if hook.If.Empty() {
-		return true, nil
+	if !hook.If.Empty() {
+		f, err := hook.If.Run(data.Convert())
+		if err != nil {
+			return false, err //nolint:wrapcheck
+		}
+		if !f {
+			return false, nil
+		}
+	}
+	f, err := data.MatchPaths(hook.Paths, hook.PathsIgnore)
+	if err != nil {
+		return false, fmt.Errorf("filter a hook by paths and paths-ignore: %w", err)
 	}
-	return hook.If.Run(data.Convert()) //nolint:wrapcheck
+	return f, nil
 }
 
-// getHook returns a hook configuration which data matches.
-// If data doesn't match any configuration, the second returned value is false.
-func getHook(data *domain.Data, repo config.Repository) (config.Hook, bool, error) {
+// getHooks returns hooks which data matches.
+// If the repository's hook-mode is "first", only the first matched hook is returned.
+func getHooks(data *domain.Data, repo config.Repository) ([]config.Hook, error) {
+	hooks := []config.Hook{}
 	for _, hook := range repo.Hooks {
 		f, err := matchHook(data, hook)
 		if err != nil {
-			return config.Hook{}, false, err
+			return nil, err
+		}
+		if !f {
+			continue
 		}
-		if f {
-			return hook, true, nil
+		hooks = append(hooks, hook)
+		if repo.HookMode != config.HookModeAll {
+			return hooks, nil
 		}
 	}
-	return config.Hook{}, false, nil
+	return hooks, nil
 }
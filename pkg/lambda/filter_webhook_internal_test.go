@@ -0,0 +1,169 @@
+package lambda
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+	"gopkg.in/yaml.v2"
+)
+
+func Test_getRepo(t *testing.T) {
+	t.Parallel()
+	cfg := config.Config{}
+	if err := yaml.Unmarshal([]byte(`
+organizations:
+- name: myorg
+  codebuild:
+    project-name: myorg-default
+  hooks:
+  - config: lambuild.yaml
+repositories:
+- name-regexp: "^myorg/svc-"
+  codebuild:
+    project-name: regexp
+- name: myorg/svc-*
+  codebuild:
+    project-name: glob
+- name: myorg/*
+- name: myorg/svc-a
+  codebuild:
+    project-name: exact
+  hooks:
+  - config: ci/lambuild.yaml
+- name: suzuki-shunsuke/lambuild
+  codebuild:
+    project-name: lambuild
+`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	data := []struct {
+		title          string
+		name           string
+		notFound       bool
+		expProjectName string
+		expConfig      string
+	}{
+		{
+			title:          "exact name takes precedence over glob",
+			name:           "myorg/svc-a",
+			expProjectName: "exact",
+			expConfig:      "ci/lambuild.yaml",
+		},
+		{
+			title:          "glob takes precedence over regexp",
+			name:           "myorg/svc-b",
+			expProjectName: "glob",
+			expConfig:      "lambuild.yaml",
+		},
+		{
+			title:          "inherit organization's configuration",
+			name:           "myorg/web",
+			expProjectName: "myorg-default",
+			expConfig:      "lambuild.yaml",
+		},
+		{
+			title:          "no organization",
+			name:           "suzuki-shunsuke/lambuild",
+			expProjectName: "lambuild",
+		},
+		{
+			title:    "glob doesn't match slash",
+			name:     "other/myorg/web",
+			notFound: true,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			repo, f := getRepo(cfg.Repositories, cfg.Organizations, d.name)
+			if d.notFound {
+				if f {
+					t.Fatal("repository shouldn't be found")
+				}
+				return
+			}
+			if !f {
+				t.Fatal("repository should be found")
+			}
+			if repo.CodeBuild.ProjectName != d.expProjectName {
+				t.Fatalf("project name: got %s, wanted %s", repo.CodeBuild.ProjectName, d.expProjectName)
+			}
+			cfgPath := ""
+			if len(repo.Hooks) != 0 {
+				cfgPath = repo.Hooks[0].Config
+			}
+			if cfgPath != d.expConfig {
+				t.Fatalf("hook config: got %s, wanted %s", cfgPath, d.expConfig)
+			}
+		})
+	}
+}
+
+func Test_getHooks(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title     string
+		src       string
+		expConfig []string
+	}{
+		{
+			title: "first",
+			src: `
+hooks:
+- if: 'event.Headers.Event == "push"'
+  config: push.yaml
+- config: lint.yaml
+- config: deploy.yaml
+`,
+			expConfig: []string{"lint.yaml"},
+		},
+		{
+			title: "all",
+			src: `
+hook-mode: all
+hooks:
+- if: 'event.Headers.Event == "push"'
+  config: push.yaml
+- config: lint.yaml
+- config: deploy.yaml
+`,
+			expConfig: []string{"lint.yaml", "deploy.yaml"},
+		},
+		{
+			title: "no hook matches",
+			src: `
+hook-mode: all
+hooks:
+- if: 'event.Headers.Event == "push"'
+  config: push.yaml
+`,
+			expConfig: []string{},
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			repo := config.Repository{}
+			if err := yaml.Unmarshal([]byte(d.src), &repo); err != nil {
+				t.Fatal(err)
+			}
+			dt := domain.NewData()
+			dt.Event.Headers.Event = "pull_request"
+			hooks, err := getHooks(&dt, repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			configs := make([]string, len(hooks))
+			for i, hook := range hooks {
+				configs[i] = hook.Config
+			}
+			if !reflect.DeepEqual(configs, d.expConfig) {
+				t.Fatalf("got %v, wanted %v", configs, d.expConfig)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package lambda
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v37/github"
+	"github.com/sirupsen/logrus"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+)
+
+const maxReviewsPerPage = 100
+
+// isForkPR returns true if the pull request's head repository is different from the base repository.
+// If the head repository has been deleted, the pull request is treated as a fork.
+func isForkPR(pr *github.PullRequest) bool {
+	headRepo := pr.GetHead().GetRepo()
+	if headRepo == nil {
+		return true
+	}
+	return headRepo.GetFullName() != pr.GetBase().GetRepo().GetFullName()
+}
+
+// hasLabel returns true if the pull request has the label.
+func hasLabel(pr *github.PullRequest, label string) bool {
+	for _, l := range pr.Labels {
+		if l.GetName() == label {
+			return true
+		}
+	}
+	return false
+}
+
+// checkForkPR checks the fork pull request policy and returns the ref where configuration files are read.
+// If builds mustn't be started, the second returned value is false.
+// If the event isn't associated with a pull request from a fork, data.Ref is returned.
+// The head branch of a fork doesn't exist in the base repository, so configuration files of forks are read at the head commit.
+func checkForkPR(ctx context.Context, logE *logrus.Entry, data *domain.Data, repo config.Repository) (string, bool, error) {
+	pr, _ := data.PullRequest.PullRequest.Peek()
+	if pr == nil || !isForkPR(pr) {
+		return data.Ref, true, nil
+	}
+	policy := repo.ForkPullRequest.Policy.Get()
+	logE = logE.WithField("fork_pull_request_policy", policy)
+	switch policy {
+	case config.ForkPolicyAllow:
+		return data.SHA, true, nil
+	case config.ForkPolicyUseBaseConfig:
+		return pr.GetBase().GetRef(), true, nil
+	case config.ForkPolicyRequireLabel:
+		return checkForkLabel(ctx, logE, data, repo.ForkPullRequest.Label, pr)
+	case config.ForkPolicyRequireApproval:
+		approved, err := isApproved(ctx, data, repo.ForkPullRequest.Permission, pr)
+		if err != nil {
+			return "", false, err
+		}
+		if approved {
+			return data.SHA, true, nil
+		}
+		// approvals don't run builds because pull_request_review events aren't supported,
+		// so builds of the approved commit are run by the command "/lambuild run".
+		logE.Info("the pull request from a fork is ignored because the head commit isn't approved")
+		return "", false, nil
+	default:
+		logE.Info("the pull request from a fork is ignored")
+		return "", false, nil
+	}
+}
+
+// checkForkLabel checks if the pull request from a fork has the label.
+// The label approves only the code at the time the label is added,
+// so if new commits are pushed the label is removed and builds aren't started until the label is added again.
+func checkForkLabel(ctx context.Context, logE *logrus.Entry, data *domain.Data, label string, pr *github.PullRequest) (string, bool, error) {
+	logE = logE.WithField("label", label)
+	if !hasLabel(pr, label) {
+		logE.Info("the pull request from a fork is ignored because the pull request doesn't have the label")
+		return "", false, nil
+	}
+	if !isPushedToPR(data) {
+		return data.SHA, true, nil
+	}
+	if err := data.GitHub.RemoveLabel(ctx, data.Repository.Owner, data.Repository.Name, pr.GetNumber(), label); err != nil {
+		return "", false, fmt.Errorf("remove the label from the pull request: %w", err)
+	}
+	logE.Info("the pull request from a fork is ignored and the label is removed because commits were pushed after the label was added")
+	return "", false, nil
+}
+
+// isPushedToPR returns true if the event is a pull_request event which may change the head commit.
+func isPushedToPR(data *domain.Data) bool {
+	ev, ok := data.Event.Payload.(*github.PullRequestEvent)
+	if !ok {
+		return false
+	}
+	switch ev.GetAction() {
+	case "synchronize", "reopened":
+		return true
+	default:
+		return false
+	}
+}
+
+// isApproved returns true if the pull request's head commit is approved by a reviewer who has the permission.
+// Approvals of old commits are ignored, because commits may be pushed after the approval.
+func isApproved(ctx context.Context, data *domain.Data, permission config.Permission, pr *github.PullRequest) (bool, error) {
+	sha := pr.GetHead().GetSHA()
+	checked := map[string]struct{}{}
+	for page := 1; ; page++ {
+		reviews, err := data.GitHub.ListReviews(ctx, data.Repository.Owner, data.Repository.Name, pr.GetNumber(), &github.ListOptions{
+			Page:    page,
+			PerPage: maxReviewsPerPage,
+		})
+		if err != nil {
+			return false, fmt.Errorf("list pull request reviews: %w", err)
+		}
+		for _, review := range reviews {
+			if review.GetState() != "APPROVED" || review.GetCommitID() != sha {
+				continue
+			}
+			reviewer := review.GetUser().GetLogin()
+			if _, ok := checked[reviewer]; ok {
+				continue
+			}
+			checked[reviewer] = struct{}{}
+			level, err := data.GitHub.GetPermissionLevel(ctx, data.Repository.Owner, data.Repository.Name, reviewer)
+			if err != nil {
+				return false, fmt.Errorf("get the reviewer's permission: %w", err)
+			}
+			if permission.Satisfied(level) {
+				return true, nil
+			}
+		}
+		if len(reviews) != maxReviewsPerPage {
+			return false, nil
+		}
+	}
+}
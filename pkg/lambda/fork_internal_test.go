@@ -0,0 +1,224 @@
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v37/github"
+	"github.com/sirupsen/logrus"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+	gh "github.com/suzuki-shunsuke/lambuild/pkg/github"
+)
+
+func newForkPR(labels ...string) *github.PullRequest {
+	pr := &github.PullRequest{
+		Number: github.Int(5),
+		Head: &github.PullRequestBranch{
+			Ref:  github.String("feature"),
+			SHA:  github.String("head"),
+			Repo: &github.Repository{FullName: github.String("octocat/test-lambuild")},
+		},
+		Base: &github.PullRequestBranch{
+			Ref:  github.String("main"),
+			Repo: &github.Repository{FullName: github.String("suzuki-shunsuke/test-lambuild")},
+		},
+	}
+	for _, label := range labels {
+		pr.Labels = append(pr.Labels, &github.Label{Name: github.String(label)})
+	}
+	return pr
+}
+
+func newApproval(sha string) *github.PullRequestReview {
+	return &github.PullRequestReview{
+		State:    github.String("APPROVED"),
+		CommitID: github.String(sha),
+		User:     &github.User{Login: github.String("suzuki-shunsuke")},
+	}
+}
+
+// setForkTestEvent sets a "push" event if pr is nil, otherwise a "pull_request" event.
+// The payloads are same as GitHub's, so "after" is set only to "synchronize" events.
+func setForkTestEvent(data *domain.Data, pr *github.PullRequest, action string) {
+	if pr == nil {
+		ev := &github.PushEvent{
+			Ref:   github.String("feature"),
+			After: github.String("head"),
+			Repo:  &github.PushEventRepository{FullName: github.String("suzuki-shunsuke/test-lambuild")},
+		}
+		data.Event.Payload = ev
+		setEventData(data, "push", ev)
+		return
+	}
+	if action == "" {
+		action = "opened"
+	}
+	ev := &github.PullRequestEvent{
+		Action:      github.String(action),
+		PullRequest: pr,
+		Repo:        pr.GetBase().GetRepo(),
+	}
+	if action == "synchronize" {
+		ev.After = github.String(pr.GetHead().GetSHA())
+	}
+	data.Event.Payload = ev
+	setEventData(data, "pull_request", ev)
+}
+
+func mustForkPolicy(t *testing.T, name string) config.ForkPolicy {
+	t.Helper()
+	policy, err := config.NewForkPolicy(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return policy
+}
+
+func Test_checkForkPR(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title      string
+		pr         *github.PullRequest
+		policy     string
+		label      string
+		action     string
+		reviews    []*github.PullRequestReview
+		expRef     string
+		expAllowed bool
+		expRemoved bool
+	}{
+		{
+			title:      "push",
+			expRef:     "feature",
+			expAllowed: true,
+		},
+		{
+			title: "not fork",
+			pr: &github.PullRequest{
+				Head: &github.PullRequestBranch{
+					Ref:  github.String("feature"),
+					SHA:  github.String("head"),
+					Repo: &github.Repository{FullName: github.String("suzuki-shunsuke/test-lambuild")},
+				},
+				Base: &github.PullRequestBranch{
+					Repo: &github.Repository{FullName: github.String("suzuki-shunsuke/test-lambuild")},
+				},
+			},
+			expRef:     "feature",
+			expAllowed: true,
+		},
+		{
+			title: "deny by default",
+			pr:    newForkPR(),
+		},
+		{
+			title:      "allow",
+			pr:         newForkPR(),
+			policy:     "allow",
+			expRef:     "head",
+			expAllowed: true,
+		},
+		{
+			title:      "use-base-config",
+			pr:         newForkPR(),
+			policy:     "use-base-config",
+			expRef:     "main",
+			expAllowed: true,
+		},
+		{
+			title:      "require-label",
+			pr:         newForkPR("ok-to-test"),
+			policy:     "require-label",
+			label:      "ok-to-test",
+			expRef:     "head",
+			expAllowed: true,
+		},
+		{
+			title:      "require-label synchronize",
+			pr:         newForkPR("ok-to-test"),
+			policy:     "require-label",
+			label:      "ok-to-test",
+			action:     "synchronize",
+			expRemoved: true,
+		},
+		{
+			title:      "require-label reopened",
+			pr:         newForkPR("ok-to-test"),
+			policy:     "require-label",
+			label:      "ok-to-test",
+			action:     "reopened",
+			expRemoved: true,
+		},
+		{
+			title:      "require-label labeled",
+			pr:         newForkPR("ok-to-test"),
+			policy:     "require-label",
+			label:      "ok-to-test",
+			action:     "labeled",
+			expRef:     "head",
+			expAllowed: true,
+		},
+		{
+			title:  "require-label synchronize without the label",
+			pr:     newForkPR("bug"),
+			policy: "require-label",
+			label:  "ok-to-test",
+			action: "synchronize",
+		},
+		{
+			title:  "require-label without the label",
+			pr:     newForkPR("bug"),
+			policy: "require-label",
+			label:  "ok-to-test",
+		},
+		{
+			title:      "require-approval",
+			pr:         newForkPR(),
+			policy:     "require-approval",
+			reviews:    []*github.PullRequestReview{newApproval("head")},
+			expRef:     "head",
+			expAllowed: true,
+		},
+		{
+			title:   "approval of an old commit is ignored",
+			pr:      newForkPR(),
+			policy:  "require-approval",
+			reviews: []*github.PullRequestReview{newApproval("old")},
+		},
+	}
+	logE := logrus.NewEntry(logrus.New())
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			dt := domain.NewData()
+			stderr := &bytes.Buffer{}
+			dt.GitHub = &gh.Local{Reviews: d.reviews, Stderr: stderr}
+			setForkTestEvent(&dt, d.pr, d.action)
+			repo := config.Repository{
+				ForkPullRequest: config.ForkPullRequest{
+					Label: d.label,
+				},
+			}
+			if d.policy != "" {
+				repo.ForkPullRequest.Policy = mustForkPolicy(t, d.policy)
+			}
+			ref, allowed, err := checkForkPR(context.Background(), logE, &dt, repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if allowed != d.expAllowed {
+				t.Fatalf("allowed: got %v, wanted %v", allowed, d.expAllowed)
+			}
+			if ref != d.expRef {
+				t.Fatalf("ref: got %s, wanted %s", ref, d.expRef)
+			}
+			if removed := strings.Contains(stderr.String(), "remove the label "+d.label); removed != d.expRemoved {
+				t.Fatalf("label removed: got %v, wanted %v", removed, d.expRemoved)
+			}
+		})
+	}
+}
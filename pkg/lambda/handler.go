@@ -2,9 +2,13 @@ package lambda
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"sync"
 
+	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/request"
@@ -16,53 +20,90 @@ import (
 	bspec "github.com/suzuki-shunsuke/lambuild/pkg/buildspec"
 	"github.com/suzuki-shunsuke/lambuild/pkg/config"
 	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
-	"golang.org/x/sync/errgroup"
 )
 
 type Handler struct {
 	Config       config.Config
 	Secret       Secret
 	GitHub       domain.GitHub
+	GitHubApp    GitHubApp
 	CodeBuild    CodeBuild
 	AWSAccountID string
 }
 
+// GitHubApp creates GitHub clients authenticated as GitHub App's installations.
+type GitHubApp interface {
+	Installation(installationID int64) domain.GitHub
+}
+
 type CodeBuild interface {
 	StartBuildBatchWithContext(ctx aws.Context, input *codebuild.StartBuildBatchInput, opts ...request.Option) (*codebuild.StartBuildBatchOutput, error)
 	StartBuildWithContext(ctx aws.Context, input *codebuild.StartBuildInput, opts ...request.Option) (*codebuild.StartBuildOutput, error)
+	ListBuildsForProjectWithContext(ctx aws.Context, input *codebuild.ListBuildsForProjectInput, opts ...request.Option) (*codebuild.ListBuildsForProjectOutput, error)
+	BatchGetBuildsWithContext(ctx aws.Context, input *codebuild.BatchGetBuildsInput, opts ...request.Option) (*codebuild.BatchGetBuildsOutput, error)
+	RetryBuildWithContext(ctx aws.Context, input *codebuild.RetryBuildInput, opts ...request.Option) (*codebuild.RetryBuildOutput, error)
+	ListBuildBatchesForProjectWithContext(ctx aws.Context, input *codebuild.ListBuildBatchesForProjectInput, opts ...request.Option) (*codebuild.ListBuildBatchesForProjectOutput, error)
+	BatchGetBuildBatchesWithContext(ctx aws.Context, input *codebuild.BatchGetBuildBatchesInput, opts ...request.Option) (*codebuild.BatchGetBuildBatchesOutput, error)
+	RetryBuildBatchWithContext(ctx aws.Context, input *codebuild.RetryBuildBatchInput, opts ...request.Option) (*codebuild.RetryBuildBatchOutput, error)
+	StopBuildWithContext(ctx aws.Context, input *codebuild.StopBuildInput, opts ...request.Option) (*codebuild.StopBuildOutput, error)
+	StopBuildBatchWithContext(ctx aws.Context, input *codebuild.StopBuildBatchInput, opts ...request.Option) (*codebuild.StopBuildBatchOutput, error)
 }
 
 type Secret struct {
-	GitHubToken   string `json:"github-token"`
-	WebhookSecret string `json:"webhook-secret"`
+	GitHubToken         string `json:"github-token"`
+	WebhookSecret       string `json:"webhook-secret"`
+	GitHubAppID         string `json:"github-app-id"`
+	GitHubAppPrivateKey string `json:"github-app-private-key"`
 }
 
 // Do is the Lambda Function's endpoint.
-func (handler *Handler) Do(ctx context.Context, event domain.Event) error {
+// Do returns the HTTP response to GitHub so that we can check the result of the delivery with GitHub Webhook's "Recent Deliveries".
+func (handler *Handler) Do(ctx context.Context, event domain.Event) (events.APIGatewayV2HTTPResponse, error) {
 	if err := github.ValidateSignature(event.Headers.Signature, []byte(event.Body), []byte(handler.Secret.WebhookSecret)); err != nil {
-		// TODO return 400
-		logrus.Debug(err)
-		return nil
+		logrus.WithError(err).Debug("validate the signature")
+		return newErrorResponse(http.StatusUnauthorized, "the signature is invalid", err), nil
 	}
 	body, err := github.ParseWebHook(event.Headers.Event, []byte(event.Body))
 	if err != nil {
-		return fmt.Errorf("parse a webhook payload: %w", err)
+		logrus.WithError(err).Debug("parse a webhook payload")
+		return newErrorResponse(http.StatusBadRequest, "failed to parse a webhook payload", err), nil
 	}
 	event.Payload = body
 
+	switch event.Headers.Event {
+	case "push", "pull_request", "issue_comment":
+	default:
+		// Events other than "push", "pull_request", and "issue_comment" aren't supported.
+		// These events are ignored.
+		return newResponse(http.StatusAccepted, ResponseBody{
+			Message: "the event is ignored because the event type isn't supported: " + event.Headers.Event,
+		}), nil
+	}
+
+	ghClient, err := handler.getGitHub(body)
+	if err != nil {
+		logrus.WithError(err).Debug("get a GitHub client")
+		return newErrorResponse(http.StatusBadRequest, "failed to get a GitHub client", err), nil
+	}
+
 	data := domain.NewData()
+	data.SetContext(ctx)
 	data.Event = event
-	data.GitHub = handler.GitHub
+	data.GitHub = ghClient
 	data.AWS.Region = handler.Config.Region
 	data.AWS.AccountID = handler.AWSAccountID
 
-	if event.Headers.Event != "push" && event.Headers.Event != "pull_request" {
-		// Events other than "push" and "pull_request" aren't supported.
-		// These events are ignored.
-		return nil
+	if event.Headers.Event == "issue_comment" {
+		return handler.handleIssueComment(ctx, &data, body.(*github.IssueCommentEvent)), nil //nolint:forcetypeassert
 	}
+	setEventData(&data, event.Headers.Event, body)
+	results, err := handler.handleEvent(ctx, &data)
+	return handler.respond(ctx, &data, results, err), nil
+}
 
-	switch event.Headers.Event {
+// setEventData sets the repository, commit, and pull request of "push" and "pull_request" events to data.
+func setEventData(data *domain.Data, eventName string, body interface{}) {
+	switch eventName {
 	case "push":
 		pushEvent := body.(*github.PushEvent) //nolint:forcetypeassert
 		repo := pushEvent.GetRepo()
@@ -70,7 +111,9 @@ func (handler *Handler) Do(ctx context.Context, event domain.Event) error {
 			FullName: repo.GetFullName(),
 			Name:     repo.GetName(),
 		}
-		data.HeadCommitMessage.Set(pushEvent.GetHeadCommit().GetMessage())
+		if headCommit := pushEvent.GetHeadCommit(); headCommit != nil {
+			data.HeadCommitMessage.Set(headCommit.GetMessage())
+		}
 		data.SHA = pushEvent.GetAfter()
 		data.Ref = pushEvent.GetRef()
 	case "pull_request":
@@ -82,19 +125,65 @@ func (handler *Handler) Do(ctx context.Context, event domain.Event) error {
 			FullName: repo.GetFullName(),
 			Name:     repo.GetName(),
 		}
-		data.SHA = prEvent.GetAfter()
+		// "after" is set only to "synchronize" events, so the head commit is got from the pull request.
+		data.SHA = pr.GetHead().GetSHA()
 		data.Ref = pr.GetHead().GetRef()
 		data.PullRequest.PullRequest.Set(pr)
 	}
 	data.Repository.Owner = strings.Split(data.Repository.FullName, "/")[0]
-	if err := handler.handleEvent(ctx, &data); err != nil {
-		handler.sendErrorNotificaiton(ctx, err, data.Repository.Owner, data.Repository.Name, data.GetPRNumber(), data.SHA)
-		return err
+}
+
+// getGitHub returns the GitHub client for the event.
+// If lambuild is authenticated as a GitHub App, the client is authenticated as the installation of the event.
+func (handler *Handler) getGitHub(payload interface{}) (domain.GitHub, error) {
+	if handler.GitHubApp == nil {
+		return handler.GitHub, nil
+	}
+	ev, ok := payload.(interface {
+		GetInstallation() *github.Installation
+	})
+	if !ok {
+		return nil, errors.New("the event doesn't have the GitHub App installation")
+	}
+	installationID := ev.GetInstallation().GetID()
+	if installationID == 0 {
+		return nil, errors.New("the GitHub App installation isn't included in the payload")
 	}
-	return nil
+	return handler.GitHubApp.Installation(installationID), nil
 }
 
-func (handler *Handler) handleEvent(ctx context.Context, data *domain.Data) error {
+// respond converts the result of the event handling to the HTTP response.
+// If an error occurs, respond sends the error notification.
+func (handler *Handler) respond(ctx context.Context, data *domain.Data, results []Result, err error) events.APIGatewayV2HTTPResponse {
+	builds := resultBuilds(results)
+	if err != nil {
+		logrus.WithError(err).Error("handle an event")
+		prNumber, numErr := data.GetPRNumber()
+		if numErr != nil {
+			// the error notification is sent to the commit
+			logrus.WithError(numErr).Error("get the pull request number")
+		}
+		handler.sendErrorNotificaiton(ctx, data.GitHub, err, results, data.Repository.Owner, data.Repository.Name, prNumber, data.SHA)
+		return newResponse(http.StatusInternalServerError, ResponseBody{
+			Message: "failed to handle the event",
+			Error:   err.Error(),
+			Builds:  builds,
+			Results: newResultBodies(results),
+		})
+	}
+	if len(builds) == 0 {
+		return newResponse(http.StatusAccepted, ResponseBody{
+			Message: "no build is started",
+		})
+	}
+	return newResponse(http.StatusOK, ResponseBody{
+		Message: "builds are started",
+		Builds:  builds,
+		Results: newResultBodies(results),
+	})
+}
+
+func (handler *Handler) handleEvent(ctx context.Context, data *domain.Data) ([]Result, error) {
 	logE := logrus.WithFields(logrus.Fields{
 		"repo_full_name": data.Repository.FullName,
 		"repo_owner":     data.Repository.Owner,
@@ -102,106 +191,232 @@ func (handler *Handler) handleEvent(ctx context.Context, data *domain.Data) erro
 		"ref":            data.Ref,
 	})
 
-	repo, f := getRepo(handler.Config.Repositories, data.Repository.FullName)
+	repo, f := getRepo(handler.Config.Repositories, handler.Config.Organizations, data.Repository.FullName)
 	if !f {
 		logE.Debug("no repo matches")
-		return nil
+		return nil, nil
 	}
+	return handler.handleRepo(ctx, logE, data, repo, command{})
+}
 
+// handleRepo finds hooks which data matches and runs the command.
+// If cmd is the zero value, builds are started based on the configuration files.
+// If multiple hooks match, hooks are handled in order.
+// Results of all configuration files are returned, and if any of them fails, a MultiError is returned.
+func (handler *Handler) handleRepo(ctx context.Context, logE *logrus.Entry, data *domain.Data, repo config.Repository, cmd command) ([]Result, error) {
 	data.AWS.CodeBuildProjectName = repo.CodeBuild.ProjectName
 
-	hook, f, err := getHook(data, repo)
+	hooks, err := getHooks(data, repo)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if !f {
+	if len(hooks) == 0 {
 		logE.Debug("no hook matches")
-		return nil
+		return nil, nil
 	}
-	if hook.ProjectName != "" {
-		data.AWS.CodeBuildProjectName = hook.ProjectName
+
+	configRef, allowed, err := checkForkPR(ctx, logE, data, repo)
+	if err != nil {
+		return nil, fmt.Errorf("check the fork pull request policy: %w", err)
+	}
+	if !allowed {
+		return nil, nil
+	}
+
+	authorized, err := handler.authorize(ctx, logE, data, repo)
+	if err != nil {
+		return nil, fmt.Errorf("authorize the user: %w", err)
+	}
+	if !authorized {
+		return nil, nil
+	}
+
+	results := []Result{}
+	for i, hook := range hooks {
+		// hooks are handled sequentially because data.AWS.CodeBuildProjectName is changed per hook
+		hookLogE := logE
+		if len(hooks) > 1 {
+			hookLogE = logE.WithField("hook_index", i)
+		}
+		results = append(results, handler.handleHook(ctx, hookLogE, data, repo, hook, cmd, configRef)...)
+	}
+	return results, resultError(results)
+}
+
+// handleHook gets configuration files of the hook and runs the command.
+// If an error occurs before configuration files are handled, the error is returned as the result of the hook's configuration path.
+func (handler *Handler) handleHook(ctx context.Context, logE *logrus.Entry, data *domain.Data, repo config.Repository, hook config.Hook, cmd command, configRef string) []Result {
+	data.AWS.CodeBuildProjectName = getProjectName(repo, hook)
+	if data.AWS.CodeBuildProjectName == "" {
+		return []Result{{Path: hook.Config, Error: errors.New("the CodeBuild project name isn't configured")}}
 	}
 	logE = logE.WithFields(logrus.Fields{
 		"config": hook.Config,
 	})
 
+	if cmd.Name == commandRetry {
+		builds, err := handler.retryBuilds(ctx, logE, data, repo, hook)
+		return []Result{{Path: hook.Config, Builds: builds, Error: err}}
+	}
+
 	// get the configuration files from the target repository
-	buildspecs, err := handler.getConfigFromRepo(ctx, logE, data, hook)
+	buildspecs, err := handler.getConfigFromRepo(ctx, logE, data, hook, configRef)
 	if err != nil {
-		return err
+		return []Result{{Path: hook.Config, Error: err}}
 	}
 	logE.WithFields(logrus.Fields{
 		"number_of_buildspecs": len(buildspecs),
 	}).Debug("get configuration files from the source repository")
 
-	var eg errgroup.Group
-	for _, buildspec := range buildspecs {
-		buildspec := buildspec
-		eg.Go(func() error {
-			return handler.handleBuildspec(ctx, logE, data, buildspec, repo, hook)
-		})
+	if cmd.Identifier != "" {
+		buildspecs = filterBuildspecsByIdentifier(buildspecs, cmd.Identifier)
+		logE.WithFields(logrus.Fields{
+			"build_identifier":     cmd.Identifier,
+			"number_of_buildspecs": len(buildspecs),
+		}).Debug("filter buildspecs by the build identifier")
+	} else {
+		// a build of the specific identifier doesn't supersede other builds
+		if err := handler.cancelSupersededBuilds(ctx, logE, data, repo, hook); err != nil {
+			return []Result{{Path: hook.Config, Error: fmt.Errorf("stop superseded builds: %w", err)}}
+		}
 	}
-	return eg.Wait() //nolint:wrapcheck
-}
 
-func (handler *Handler) handleBuildspec(ctx context.Context, logE *logrus.Entry, data *domain.Data, buildspec bspec.Buildspec, repo config.Repository, hook config.Hook) error {
-	buildInput, err := generator.GenerateInput(logE, handler.Config.BuildStatusContext, data, buildspec, repo)
-	if err != nil {
-		logE.WithError(err).Error("generate a build input")
-		return fmt.Errorf("generate a build input: %w", err)
+	var wg sync.WaitGroup
+	results := make([]Result, len(buildspecs))
+	for i, file := range buildspecs {
+		results[i].Path = file.Path
+		if file.Err != nil {
+			logE.WithField("path", file.Path).WithError(file.Err).Error("read a configuration file")
+			results[i].Error = file.Err
+			continue
+		}
+		i := i
+		file := file
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i].Builds, results[i].Error = handler.handleBuildspec(ctx, logE.WithField("path", file.Path), data, file.Buildspec, repo, hook)
+		}()
 	}
+	wg.Wait()
+	return results
+}
 
-	if buildInput.Empty {
-		return nil
+// setBuildInputParams sets the CodeBuild Project name, the source version, the service role, and the auto-cancel key to the build input.
+func setBuildInputParams(buildInput *domain.BuildInput, data *domain.Data, repo config.Repository, hook config.Hook) {
+	setAutoCancelKey(buildInput, getAutoCancelKey(data, repo, hook))
+	projectName := getProjectName(repo, hook)
+	if buildInput.Batched {
+		buildInput.BatchBuild.ProjectName = aws.String(projectName)
+		buildInput.BatchBuild.SourceVersion = aws.String(data.SHA)
+		if hook.ServiceRole != "" {
+			buildInput.BatchBuild.ServiceRoleOverride = aws.String(hook.ServiceRole)
+			if batchConfig := buildInput.BatchBuild.BuildBatchConfigOverride; batchConfig != nil {
+				batchConfig.ServiceRole = aws.String(hook.ServiceRole)
+			}
+		}
+		return
+	}
+	for _, build := range buildInput.Builds {
+		build.ProjectName = aws.String(projectName)
+		build.SourceVersion = aws.String(data.SHA)
+		if hook.ServiceRole != "" {
+			build.ServiceRoleOverride = aws.String(hook.ServiceRole)
+		}
 	}
+}
 
-	projectName := repo.CodeBuild.ProjectName
+// getProjectName returns the CodeBuild Project name.
+// hook's project name takes precedence over repo's project name.
+func getProjectName(repo config.Repository, hook config.Hook) string {
 	if hook.ProjectName != "" {
-		projectName = hook.ProjectName
+		return hook.ProjectName
 	}
+	return repo.CodeBuild.ProjectName
+}
 
-	cb := handler.CodeBuild
+// getCodeBuild returns the CodeBuild client.
+// If the assume role is configured, the client assumes the role.
+func (handler *Handler) getCodeBuild(repo config.Repository, hook config.Hook) CodeBuild {
 	assumeRoleARN := repo.CodeBuild.AssumeRoleARN
 	if hook.AssumeRoleARN != "" {
 		assumeRoleARN = hook.AssumeRoleARN
 	}
-	if assumeRoleARN != "" {
-		sess := session.Must(session.NewSession())
-		creds := stscreds.NewCredentials(sess, assumeRoleARN)
-		cb = codebuild.New(sess, &aws.Config{Credentials: creds, Region: aws.String(handler.Config.Region)})
+	if assumeRoleARN == "" {
+		return handler.CodeBuild
+	}
+	sess := session.Must(session.NewSession())
+	creds := stscreds.NewCredentials(sess, assumeRoleARN)
+	return codebuild.New(sess, &aws.Config{Credentials: creds, Region: aws.String(handler.Config.Region)})
+}
+
+// generateBuildInput generates a build input from the buildspec, sets parameters of the repository and the hook, and checks the build input.
+// The handler and the renderer share this function so that rendered inputs are same as inputs of started builds.
+func (handler *Handler) generateBuildInput(logE *logrus.Entry, data *domain.Data, buildspec bspec.Buildspec, repo config.Repository, hook config.Hook) (domain.BuildInput, error) {
+	buildInput, err := generator.GenerateInput(logE, handler.Config.BuildStatusContext.Template(), data, buildspec, repo)
+	if err != nil {
+		return buildInput, fmt.Errorf("generate a build input: %w", err)
+	}
+	if buildInput.Empty {
+		return buildInput, nil
+	}
+	setBuildInputParams(&buildInput, data, repo, hook)
+	if err := checkOverrides(&buildInput, hook.AllowedOverrides); err != nil {
+		return buildInput, fmt.Errorf("check overrides: %w", err)
+	}
+	if err := checkBuildPolicy(&buildInput, getBuildPolicy(repo, hook)); err != nil {
+		return buildInput, fmt.Errorf("check build policy: %w", err)
+	}
+	if err := checkSecretEnvVars(&buildInput, repo.AllowedSecretPrefixes); err != nil {
+		return buildInput, fmt.Errorf("check environment variables: %w", err)
+	}
+	return buildInput, nil
+}
+
+// handleBuildspec starts builds of a buildspec and returns started builds.
+// Even if an error occurs, builds started before the error are returned.
+func (handler *Handler) handleBuildspec(ctx context.Context, logE *logrus.Entry, data *domain.Data, buildspec bspec.Buildspec, repo config.Repository, hook config.Hook) ([]Build, error) {
+	buildInput, err := handler.generateBuildInput(logE, data, buildspec, repo, hook)
+	if err != nil {
+		logE.WithError(err).Error("generate a build input")
+		return nil, err
+	}
+	if buildInput.Empty {
+		return nil, nil
 	}
 
+	cb := handler.getCodeBuild(repo, hook)
+
 	if buildInput.Batched {
-		buildInput.BatchBuild.ProjectName = aws.String(projectName)
-		buildInput.BatchBuild.SourceVersion = aws.String(data.SHA)
-		if hook.ServiceRole != "" {
-			buildInput.BatchBuild.ServiceRoleOverride = aws.String(hook.ServiceRole)
-		}
 		buildOut, err := cb.StartBuildBatchWithContext(ctx, buildInput.BatchBuild)
 		if err != nil {
 			logE.WithError(err).Error("start a batch build")
-			return fmt.Errorf("start a batch build: %w", err)
+			return nil, fmt.Errorf("start a batch build: %w", err)
 		}
 		logE.WithFields(logrus.Fields{
 			"build_arn": *buildOut.BuildBatch.Arn,
 		}).Info("start a batch build")
-		return nil
+		return []Build{
+			{
+				ARN:     *buildOut.BuildBatch.Arn,
+				Batched: true,
+			},
+		}, nil
 	}
 
+	builds := make([]Build, 0, len(buildInput.Builds))
 	for _, build := range buildInput.Builds {
-		build.ProjectName = aws.String(projectName)
-		build.SourceVersion = aws.String(data.SHA)
-		if hook.ServiceRole != "" {
-			build.ServiceRoleOverride = aws.String(hook.ServiceRole)
-		}
 		buildOut, err := cb.StartBuildWithContext(ctx, build)
 		if err != nil {
 			logE.WithError(err).Error("start a build")
-			return fmt.Errorf("start a build: %w", err)
+			return builds, fmt.Errorf("start a build: %w", err)
 		}
 		logE.WithFields(logrus.Fields{
 			"build_arn": *buildOut.Build.Arn,
 		}).Info("start a build")
+		builds = append(builds, Build{
+			ARN: *buildOut.Build.Arn,
+		})
 	}
-	return nil
+	return builds, nil
 }
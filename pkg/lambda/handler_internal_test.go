@@ -0,0 +1,186 @@
+package lambda
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v37/github"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+)
+
+const testWebhookSecret = "secret"
+
+func signPayload(body string) string {
+	mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// commentClient records comments of the error notification.
+type commentClient struct {
+	domain.GitHub
+	comments []string
+}
+
+func (client *commentClient) GetPRsWithCommit(ctx context.Context, owner, repo string, sha string) ([]*github.PullRequest, error) {
+	return nil, nil
+}
+
+func (client *commentClient) CreateCommitComment(ctx context.Context, owner, repo, sha, body string) error {
+	client.comments = append(client.comments, body)
+	return nil
+}
+
+type testGitHubApp struct{}
+
+func (app testGitHubApp) Installation(installationID int64) domain.GitHub {
+	return nil
+}
+
+func TestHandler_Do(t *testing.T) {
+	t.Parallel()
+	pushBody := `{"ref":"refs/heads/main","after":"0123456","repository":{"name":"test-lambuild","full_name":"suzuki-shunsuke/test-lambuild"}}`
+	data := []struct {
+		title      string
+		event      domain.Event
+		repos      []config.Repository
+		app        GitHubApp
+		statusCode int
+		message    string
+		isErr      bool
+		comments   int
+	}{
+		{
+			title: "invalid signature",
+			event: domain.Event{
+				Body: pushBody,
+				Headers: domain.Headers{
+					Event:     "push",
+					Signature: "sha256=invalid",
+				},
+			},
+			statusCode: http.StatusUnauthorized,
+			message:    "the signature is invalid",
+			isErr:      true,
+		},
+		{
+			title: "invalid payload",
+			event: domain.Event{
+				Body: "{",
+				Headers: domain.Headers{
+					Event:     "push",
+					Signature: signPayload("{"),
+				},
+			},
+			statusCode: http.StatusBadRequest,
+			message:    "failed to parse a webhook payload",
+			isErr:      true,
+		},
+		{
+			title: "unsupported event",
+			event: domain.Event{
+				Body: "{}",
+				Headers: domain.Headers{
+					Event:     "ping",
+					Signature: signPayload("{}"),
+				},
+			},
+			statusCode: http.StatusAccepted,
+			message:    "the event is ignored because the event type isn't supported: ping",
+		},
+		{
+			title: "no GitHub App installation",
+			event: domain.Event{
+				Body: pushBody,
+				Headers: domain.Headers{
+					Event:     "push",
+					Signature: signPayload(pushBody),
+				},
+			},
+			app:        testGitHubApp{},
+			statusCode: http.StatusBadRequest,
+			message:    "failed to get a GitHub client",
+			isErr:      true,
+		},
+		{
+			title: "no repository matches",
+			event: domain.Event{
+				Body: pushBody,
+				Headers: domain.Headers{
+					Event:     "push",
+					Signature: signPayload(pushBody),
+				},
+			},
+			statusCode: http.StatusAccepted,
+			message:    "no build is started",
+		},
+		{
+			title: "failed to handle the event",
+			event: domain.Event{
+				Body: pushBody,
+				Headers: domain.Headers{
+					Event:     "push",
+					Signature: signPayload(pushBody),
+				},
+			},
+			repos: []config.Repository{
+				{
+					Name: "suzuki-shunsuke/test-lambuild",
+					// the CodeBuild project name isn't configured
+					Hooks: []config.Hook{{Config: "lambuild.yaml"}},
+				},
+			},
+			statusCode: http.StatusInternalServerError,
+			message:    "failed to handle the event",
+			isErr:      true,
+			// the error is notified to the commit
+			comments: 1,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			ghClient := &commentClient{}
+			handler := &Handler{
+				Config: config.Config{
+					Repositories: d.repos,
+				},
+				Secret: Secret{
+					WebhookSecret: testWebhookSecret,
+				},
+				GitHub:    ghClient,
+				GitHubApp: d.app,
+			}
+			resp, err := handler.Do(context.Background(), d.event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != d.statusCode {
+				t.Fatalf("got %d, wanted %d: %s", resp.StatusCode, d.statusCode, resp.Body)
+			}
+			body := ResponseBody{}
+			if err := json.Unmarshal([]byte(resp.Body), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Message != d.message {
+				t.Fatalf(`got "%s", wanted "%s"`, body.Message, d.message)
+			}
+			if d.isErr && body.Error == "" {
+				t.Fatal("the error should be included in the response body")
+			}
+			if !d.isErr && body.Error != "" {
+				t.Fatalf("the error shouldn't be included in the response body: %s", body.Error)
+			}
+			if len(ghClient.comments) != d.comments {
+				t.Fatalf("got %d comments, wanted %d", len(ghClient.comments), d.comments)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package lambda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-github/v37/github"
+	"github.com/sirupsen/logrus"
+	bspec "github.com/suzuki-shunsuke/lambuild/pkg/buildspec"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+)
+
+const (
+	commandPrefix = "/lambuild"
+	commandRun    = "run"
+	commandRetry  = "retry"
+)
+
+// command is a command in a pull request comment.
+//
+// /lambuild run [<build identifier>]
+// /lambuild retry
+type command struct {
+	Name       string
+	Identifier string
+}
+
+// parseCommand parses the first line of a comment as a command.
+// If the comment isn't a lambuild's command, the second returned value is false.
+func parseCommand(comment string) (command, bool, error) {
+	line := strings.TrimSpace(strings.SplitN(comment, "\n", 2)[0]) //nolint:gomnd
+	fields := strings.Fields(line)
+	if len(fields) == 0 || fields[0] != commandPrefix {
+		return command{}, false, nil
+	}
+	if len(fields) == 1 {
+		return command{}, true, errors.New("sub command is required. Usage: /lambuild run [<build identifier>] | /lambuild retry")
+	}
+	switch fields[1] {
+	case commandRun:
+		switch len(fields) {
+		case 2: //nolint:gomnd
+			return command{Name: commandRun}, true, nil
+		case 3: //nolint:gomnd
+			return command{Name: commandRun, Identifier: fields[2]}, true, nil
+		default:
+			return command{}, true, errors.New("too many arguments. Usage: /lambuild run [<build identifier>]")
+		}
+	case commandRetry:
+		if len(fields) != 2 { //nolint:gomnd
+			return command{}, true, errors.New("too many arguments. Usage: /lambuild retry")
+		}
+		return command{Name: commandRetry}, true, nil
+	default:
+		return command{}, true, errors.New("unknown sub command: " + fields[1])
+	}
+}
+
+// handleIssueComment handles commands in pull request comments.
+// The commit is the head commit of the pull request.
+func (handler *Handler) handleIssueComment(ctx context.Context, data *domain.Data, event *github.IssueCommentEvent) events.APIGatewayV2HTTPResponse {
+	if event.GetAction() != "created" {
+		return newResponse(http.StatusAccepted, ResponseBody{
+			Message: "the event is ignored because the action isn't created: " + event.GetAction(),
+		})
+	}
+	if !event.GetIssue().IsPullRequest() {
+		return newResponse(http.StatusAccepted, ResponseBody{
+			Message: "the event is ignored because the comment isn't a pull request comment",
+		})
+	}
+	cmd, f, err := parseCommand(event.GetComment().GetBody())
+	if err != nil {
+		return newErrorResponse(http.StatusBadRequest, "the command is invalid", err)
+	}
+	if !f {
+		return newResponse(http.StatusAccepted, ResponseBody{
+			Message: "the event is ignored because the comment isn't a command",
+		})
+	}
+
+	ghRepo := event.GetRepo()
+	data.Repository = domain.Repository{
+		FullName: ghRepo.GetFullName(),
+		Owner:    ghRepo.GetOwner().GetLogin(),
+		Name:     ghRepo.GetName(),
+	}
+	prNumber := event.GetIssue().GetNumber()
+	data.PullRequest.Number.Set(prNumber)
+
+	logE := logrus.WithFields(logrus.Fields{
+		"repo_full_name": data.Repository.FullName,
+		"repo_owner":     data.Repository.Owner,
+		"repo_name":      data.Repository.Name,
+		"pr_number":      prNumber,
+		"command":        cmd.Name,
+		"commenter":      event.GetComment().GetUser().GetLogin(),
+	})
+
+	repo, f := getRepo(handler.Config.Repositories, handler.Config.Organizations, data.Repository.FullName)
+	if !f {
+		logE.Debug("no repo matches")
+		return newResponse(http.StatusAccepted, ResponseBody{
+			Message: "the event is ignored because no repository matches",
+		})
+	}
+	if repo.IssueComment.Disabled {
+		return newResponse(http.StatusAccepted, ResponseBody{
+			Message: "the event is ignored because commands are disabled",
+		})
+	}
+
+	commenter := event.GetComment().GetUser().GetLogin()
+	level, err := data.GitHub.GetPermissionLevel(ctx, data.Repository.Owner, data.Repository.Name, commenter)
+	if err != nil {
+		logE.WithError(err).Error("get the commenter's permission")
+		return newErrorResponse(http.StatusInternalServerError, "failed to get the commenter's permission", err)
+	}
+	if !repo.IssueComment.Permission.Satisfied(level) {
+		logE.WithFields(logrus.Fields{
+			"permission":          level,
+			"required_permission": repo.IssueComment.Permission.Get(),
+		}).Info("the commenter doesn't have the permission to run the command")
+		return newResponse(http.StatusForbidden, ResponseBody{
+			Message: fmt.Sprintf("the commenter doesn't have the permission to run the command. the required permission is %s but the commenter's permission is %s", repo.IssueComment.Permission.Get(), level),
+		})
+	}
+
+	pr, err := data.GitHub.GetPR(ctx, data.Repository.Owner, data.Repository.Name, prNumber)
+	if err != nil {
+		logE.WithError(err).Error("get a pull request")
+		return handler.respond(ctx, data, nil, fmt.Errorf("get a pull request: %w", err))
+	}
+	data.PullRequest.PullRequest.Set(pr)
+	data.SHA = pr.GetHead().GetSHA()
+	data.Ref = pr.GetHead().GetRef()
+	logE = logE.WithFields(logrus.Fields{
+		"ref": data.Ref,
+		"sha": data.SHA,
+	})
+
+	results, err := handler.handleRepo(ctx, logE, data, repo, cmd)
+	return handler.respond(ctx, data, results, err)
+}
+
+// filterBuildspecsByIdentifier returns buildspecs which have a build-graph or build-list element whose identifier is the given identifier.
+// Elements other than the given identifier are removed, and the element's dependencies are removed too,
+// so only a build of the given identifier is run.
+// Invalid files are kept to report their errors.
+func filterBuildspecsByIdentifier(files []buildspecFile, identifier string) []buildspecFile {
+	ret := make([]buildspecFile, 0, len(files))
+	for _, file := range files {
+		if file.Err != nil {
+			ret = append(ret, file)
+			continue
+		}
+		buildspec := file.Buildspec
+		for _, elem := range buildspec.Batch.BuildGraph {
+			if elem.Identifier != identifier {
+				continue
+			}
+			elem.DependOn = nil
+			buildspec.Batch.BuildGraph = []bspec.GraphElement{elem}
+			ret = append(ret, buildspecFile{Path: file.Path, Buildspec: buildspec})
+			break
+		}
+		for _, elem := range buildspec.Batch.BuildList {
+			if elem.Identifier != identifier {
+				continue
+			}
+			buildspec.Batch.BuildList = []bspec.ListElement{elem}
+			ret = append(ret, buildspecFile{Path: file.Path, Buildspec: buildspec})
+			break
+		}
+	}
+	return ret
+}
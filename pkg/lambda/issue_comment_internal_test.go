@@ -0,0 +1,141 @@
+package lambda
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	bspec "github.com/suzuki-shunsuke/lambuild/pkg/buildspec"
+)
+
+func Test_parseCommand(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title   string
+		comment string
+		exp     command
+		f       bool
+		isErr   bool
+	}{
+		{
+			title:   "not command",
+			comment: "LGTM",
+		},
+		{
+			title:   "run",
+			comment: "/lambuild run",
+			exp:     command{Name: commandRun},
+			f:       true,
+		},
+		{
+			title:   "run with identifier",
+			comment: "/lambuild run test\nplease",
+			exp:     command{Name: commandRun, Identifier: "test"},
+			f:       true,
+		},
+		{
+			title:   "retry",
+			comment: "  /lambuild retry  ",
+			exp:     command{Name: commandRetry},
+			f:       true,
+		},
+		{
+			title:   "no sub command",
+			comment: "/lambuild",
+			f:       true,
+			isErr:   true,
+		},
+		{
+			title:   "unknown sub command",
+			comment: "/lambuild stop",
+			f:       true,
+			isErr:   true,
+		},
+		{
+			title:   "retry doesn't accept arguments",
+			comment: "/lambuild retry foo",
+			f:       true,
+			isErr:   true,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			cmd, f, err := parseCommand(d.comment)
+			if d.isErr {
+				if err == nil {
+					t.Fatal("error should be returned")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f != d.f {
+				t.Fatalf("got %v, wanted %v", f, d.f)
+			}
+			if diff := cmp.Diff(d.exp, cmd); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func Test_filterBuildspecsByIdentifier(t *testing.T) {
+	t.Parallel()
+	files := []buildspecFile{
+		{
+			Path: "build.yaml",
+			Buildspec: bspec.Buildspec{
+				Batch: bspec.Batch{
+					BuildGraph: []bspec.GraphElement{
+						{Identifier: "build"},
+						{Identifier: "test", DependOn: []string{"build"}},
+					},
+				},
+			},
+		},
+		{
+			Path: "lint.yaml",
+			Buildspec: bspec.Buildspec{
+				Batch: bspec.Batch{
+					BuildList: []bspec.ListElement{
+						{Identifier: "lint"},
+					},
+				},
+			},
+		},
+	}
+	specs := filterBuildspecsByIdentifier(files, "test")
+	if len(specs) != 1 {
+		t.Fatalf("got %d buildspecs, wanted 1", len(specs))
+	}
+	if specs[0].Path != "build.yaml" {
+		t.Fatalf(`got %s, wanted "build.yaml"`, specs[0].Path)
+	}
+	graph := specs[0].Buildspec.Batch.BuildGraph
+	if len(graph) != 1 {
+		t.Fatalf("got %d graph elements, wanted 1", len(graph))
+	}
+	if graph[0].Identifier != "test" {
+		t.Fatalf(`got %s, wanted "test"`, graph[0].Identifier)
+	}
+	if len(graph[0].DependOn) != 0 {
+		t.Fatal("depend-on should be removed")
+	}
+}
+
+func Test_filterBuildspecsByIdentifier_invalidFile(t *testing.T) {
+	t.Parallel()
+	files := []buildspecFile{
+		{
+			Path: "broken.yaml",
+			Err:  errors.New("invalid"),
+		},
+	}
+	specs := filterBuildspecsByIdentifier(files, "test")
+	if len(specs) != 1 || specs[0].Err == nil {
+		t.Fatal("invalid files should be kept")
+	}
+}
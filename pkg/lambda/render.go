@@ -0,0 +1,86 @@
+package lambda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/go-github/v37/github"
+	"github.com/sirupsen/logrus"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+)
+
+// RenderedInput is a build input generated from a configuration file.
+type RenderedInput struct {
+	Path  string
+	Input domain.BuildInput
+}
+
+// Render generates build inputs of the event without starting builds.
+// The signature of the event isn't validated.
+// If configPath isn't empty, configPath is used instead of the hook's configuration file path.
+func (handler *Handler) Render(ctx context.Context, event domain.Event, configPath string) ([]RenderedInput, error) {
+	if event.Headers.Event != "push" && event.Headers.Event != "pull_request" {
+		return nil, errors.New(`the event must be either "push" or "pull_request": ` + event.Headers.Event)
+	}
+	body, err := github.ParseWebHook(event.Headers.Event, []byte(event.Body))
+	if err != nil {
+		return nil, fmt.Errorf("parse a webhook payload: %w", err)
+	}
+	event.Payload = body
+
+	data := domain.NewData()
+	data.SetContext(ctx)
+	data.Event = event
+	data.GitHub = handler.GitHub
+	data.AWS.Region = handler.Config.Region
+	data.AWS.AccountID = handler.AWSAccountID
+	setEventData(&data, event.Headers.Event, body)
+
+	logE := logrus.WithFields(logrus.Fields{
+		"repo_full_name": data.Repository.FullName,
+		"ref":            data.Ref,
+	})
+
+	repo, f := getRepo(handler.Config.Repositories, handler.Config.Organizations, data.Repository.FullName)
+	if !f {
+		return nil, errors.New("no repository matches: " + data.Repository.FullName)
+	}
+	data.AWS.CodeBuildProjectName = repo.CodeBuild.ProjectName
+
+	hooks, err := getHooks(&data, repo)
+	if err != nil {
+		return nil, err
+	}
+	if len(hooks) == 0 {
+		return nil, errors.New("no hook matches")
+	}
+
+	inputs := []RenderedInput{}
+	for _, hook := range hooks {
+		data.AWS.CodeBuildProjectName = getProjectName(repo, hook)
+		if configPath != "" {
+			hook.Config = configPath
+		}
+
+		buildspecs, err := handler.getConfigFromRepo(ctx, logE, &data, hook, data.Ref)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range buildspecs {
+			if file.Err != nil {
+				return nil, fmt.Errorf("read a configuration file (%s): %w", file.Path, file.Err)
+			}
+			buildInput, err := handler.generateBuildInput(logE, &data, file.Buildspec, repo, hook)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file.Path, err)
+			}
+			inputs = append(inputs, RenderedInput{
+				Path:  file.Path,
+				Input: buildInput,
+			})
+		}
+	}
+	return inputs, nil
+}
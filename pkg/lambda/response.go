@@ -0,0 +1,49 @@
+package lambda
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/sirupsen/logrus"
+)
+
+// Build is a build which lambuild started.
+type Build struct {
+	ARN     string `json:"arn"`
+	Batched bool   `json:"batched"`
+}
+
+// ResponseBody is the response body which is returned to GitHub.
+// The response body is shown at the webhook's "Recent Deliveries".
+type ResponseBody struct {
+	Message string       `json:"message"`
+	Error   string       `json:"error,omitempty"`
+	Builds  []Build      `json:"builds,omitempty"`
+	Results []ResultBody `json:"results,omitempty"`
+}
+
+func newResponse(statusCode int, body ResponseBody) events.APIGatewayV2HTTPResponse {
+	b, err := json.Marshal(body)
+	if err != nil {
+		// json.Marshal never fails because ResponseBody, ResultBody, and Build have only strings, bools, and slices of these structs
+		logrus.WithError(err).Error("marshal a response body as JSON")
+		return events.APIGatewayV2HTTPResponse{
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: statusCode,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Body: string(b),
+	}
+}
+
+func newErrorResponse(statusCode int, msg string, err error) events.APIGatewayV2HTTPResponse {
+	return newResponse(statusCode, ResponseBody{
+		Message: msg,
+		Error:   err.Error(),
+	})
+}
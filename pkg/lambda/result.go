@@ -0,0 +1,75 @@
+package lambda
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Result is the result of a configuration file.
+// Builds are builds which were started from the file, and Error is the error which occurred while the file was handled.
+// Even if Error isn't nil, some builds may have been started.
+type Result struct {
+	Path   string
+	Builds []Build
+	Error  error
+}
+
+// ResultBody is Result in the response body.
+type ResultBody struct {
+	Path   string  `json:"path"`
+	Builds []Build `json:"builds,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
+func newResultBodies(results []Result) []ResultBody {
+	bodies := make([]ResultBody, len(results))
+	for i, result := range results {
+		bodies[i] = ResultBody{
+			Path:   result.Path,
+			Builds: result.Builds,
+		}
+		if result.Error != nil {
+			bodies[i].Error = result.Error.Error()
+		}
+	}
+	return bodies
+}
+
+// resultBuilds returns builds of all results.
+func resultBuilds(results []Result) []Build {
+	builds := []Build{}
+	for _, result := range results {
+		builds = append(builds, result.Builds...)
+	}
+	return builds
+}
+
+// resultError returns a MultiError of failed results.
+// If no result fails, nil is returned.
+func resultError(results []Result) error {
+	var errs MultiError
+	for _, result := range results {
+		if result.Error == nil {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", result.Path, result.Error))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// MultiError is errors which occurred independently.
+type MultiError []error
+
+func (errs MultiError) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = "* " + e.Error()
+	}
+	return fmt.Sprintf("%d errors occurred:\n%s", len(errs), strings.Join(msgs, "\n"))
+}
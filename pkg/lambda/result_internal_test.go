@@ -0,0 +1,63 @@
+package lambda
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_resultError(t *testing.T) {
+	t.Parallel()
+	errFoo := errors.New("foo")
+	data := []struct {
+		title   string
+		results []Result
+		exp     string
+	}{
+		{
+			title: "no error",
+			results: []Result{
+				{Path: "a.yaml", Builds: []Build{{ARN: "a"}}},
+			},
+		},
+		{
+			title: "an error",
+			results: []Result{
+				{Path: "a.yaml", Builds: []Build{{ARN: "a"}}},
+				{Path: "b.yaml", Error: errFoo},
+			},
+			exp: "b.yaml: foo",
+		},
+		{
+			title: "multiple errors",
+			results: []Result{
+				{Path: "a.yaml", Error: errFoo},
+				{Path: "b.yaml", Builds: []Build{{ARN: "b"}}},
+				{Path: "c.yaml", Error: errors.New("bar")},
+			},
+			exp: "2 errors occurred:\n* a.yaml: foo\n* c.yaml: bar",
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			err := resultError(d.results)
+			if d.exp == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("error should be returned")
+			}
+			if err.Error() != d.exp {
+				t.Fatalf("got %q, wanted %q", err.Error(), d.exp)
+			}
+			var errs MultiError
+			if !errors.As(err, &errs) {
+				t.Fatal("error should be MultiError")
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package lambda
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+	"github.com/sirupsen/logrus"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+)
+
+// maxRetryCandidates is the number of recent builds which are checked whether they should be retried.
+const maxRetryCandidates = 100
+
+// isRetried returns true if the build is associated with the commit and failed.
+func isRetried(sourceVersion, status, sha string) bool {
+	if sourceVersion != sha {
+		return false
+	}
+	switch status {
+	case codebuild.StatusTypeFailed, codebuild.StatusTypeFault, codebuild.StatusTypeTimedOut, codebuild.StatusTypeStopped:
+		return true
+	}
+	return false
+}
+
+// retryBuilds retries failed builds and batch builds of the commit.
+func (handler *Handler) retryBuilds(ctx context.Context, logE *logrus.Entry, data *domain.Data, repo config.Repository, hook config.Hook) ([]Build, error) {
+	cb := handler.getCodeBuild(repo, hook)
+	projectName := getProjectName(repo, hook)
+	batches, err := retryBuildBatches(ctx, logE, cb, projectName, data.SHA)
+	if err != nil {
+		return batches, err
+	}
+	builds, err := retrySingleBuilds(ctx, logE, cb, projectName, data.SHA)
+	return append(batches, builds...), err
+}
+
+func retryBuildBatches(ctx context.Context, logE *logrus.Entry, cb CodeBuild, projectName, sha string) ([]Build, error) {
+	listOut, err := cb.ListBuildBatchesForProjectWithContext(ctx, &codebuild.ListBuildBatchesForProjectInput{
+		ProjectName: aws.String(projectName),
+		SortOrder:   aws.String(codebuild.SortOrderTypeDescending),
+		MaxResults:  aws.Int64(maxRetryCandidates),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list batch builds: %w", err)
+	}
+	if len(listOut.Ids) == 0 {
+		return nil, nil
+	}
+	getOut, err := cb.BatchGetBuildBatchesWithContext(ctx, &codebuild.BatchGetBuildBatchesInput{
+		Ids: listOut.Ids,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get batch builds: %w", err)
+	}
+	builds := []Build{}
+	for _, batch := range getOut.BuildBatches {
+		if !isRetried(aws.StringValue(batch.SourceVersion), aws.StringValue(batch.BuildBatchStatus), sha) {
+			continue
+		}
+		out, err := cb.RetryBuildBatchWithContext(ctx, &codebuild.RetryBuildBatchInput{
+			Id:        batch.Id,
+			RetryType: aws.String(codebuild.RetryBuildBatchTypeRetryFailedBuilds),
+		})
+		if err != nil {
+			logE.WithError(err).Error("retry a batch build")
+			return builds, fmt.Errorf("retry a batch build (%s): %w", aws.StringValue(batch.Id), err)
+		}
+		logE.WithFields(logrus.Fields{
+			"build_arn": aws.StringValue(out.BuildBatch.Arn),
+		}).Info("retry a batch build")
+		builds = append(builds, Build{
+			ARN:     aws.StringValue(out.BuildBatch.Arn),
+			Batched: true,
+		})
+	}
+	return builds, nil
+}
+
+// getBuildKey returns the key to distinguish builds of the same commit.
+// Builds which have the same buildspec and build status context are treated as the same build.
+func getBuildKey(build *codebuild.Build) string {
+	if build.Source == nil {
+		return ""
+	}
+	key := aws.StringValue(build.Source.Buildspec)
+	if build.Source.BuildStatusConfig != nil {
+		key += "\n" + aws.StringValue(build.Source.BuildStatusConfig.Context)
+	}
+	return key
+}
+
+func retrySingleBuilds(ctx context.Context, logE *logrus.Entry, cb CodeBuild, projectName, sha string) ([]Build, error) {
+	listOut, err := cb.ListBuildsForProjectWithContext(ctx, &codebuild.ListBuildsForProjectInput{
+		ProjectName: aws.String(projectName),
+		SortOrder:   aws.String(codebuild.SortOrderTypeDescending),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list builds: %w", err)
+	}
+	ids := listOut.Ids
+	if len(ids) > maxRetryCandidates {
+		ids = ids[:maxRetryCandidates]
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	getOut, err := cb.BatchGetBuildsWithContext(ctx, &codebuild.BatchGetBuildsInput{
+		Ids: ids,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get builds: %w", err)
+	}
+	builds := []Build{}
+	// builds are sorted in descending order, so only the latest build of the same key is retried
+	checkedKeys := map[string]struct{}{}
+	for _, build := range getOut.Builds {
+		if build.BuildBatchArn != nil {
+			// builds in batch builds are retried by RetryBuildBatch
+			continue
+		}
+		if aws.StringValue(build.SourceVersion) != sha {
+			continue
+		}
+		key := getBuildKey(build)
+		if _, ok := checkedKeys[key]; ok {
+			continue
+		}
+		checkedKeys[key] = struct{}{}
+		if !isRetried(aws.StringValue(build.SourceVersion), aws.StringValue(build.BuildStatus), sha) {
+			continue
+		}
+		out, err := cb.RetryBuildWithContext(ctx, &codebuild.RetryBuildInput{
+			Id: build.Id,
+		})
+		if err != nil {
+			logE.WithError(err).Error("retry a build")
+			return builds, fmt.Errorf("retry a build (%s): %w", aws.StringValue(build.Id), err)
+		}
+		logE.WithFields(logrus.Fields{
+			"build_arn": aws.StringValue(out.Build.Arn),
+		}).Info("retry a build")
+		builds = append(builds, Build{
+			ARN: aws.StringValue(out.Build.Arn),
+		})
+	}
+	return builds, nil
+}
@@ -0,0 +1,123 @@
+package lambda
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+	bspec "github.com/suzuki-shunsuke/lambuild/pkg/buildspec"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+	"gopkg.in/yaml.v2"
+)
+
+// secretEnv is env of the rendered buildspec.
+type secretEnv struct {
+	Env struct {
+		ParameterStore map[string]string `yaml:"parameter-store"`
+		SecretsManager map[string]string `yaml:"secrets-manager"`
+	}
+}
+
+// checkSecretEnvVars returns an error if PARAMETER_STORE or SECRETS_MANAGER environment variables
+// or env.parameter-store and env.secrets-manager of the rendered buildspec
+// refer to parameters or secrets which aren't allowed.
+// env of the rendered buildspec is checked only if allowed-secret-prefixes is configured,
+// because it is the standard buildspec's feature and was available before allowed-secret-prefixes was added.
+// Buildspec files which aren't rendered by lambuild such as buildspecs of batch build elements aren't checked.
+func checkSecretEnvVars(buildInput *domain.BuildInput, prefixes config.SecretPrefixes) error {
+	if buildInput.Batched {
+		if err := checkSecretEnvVarList(buildInput.BatchBuild.EnvironmentVariablesOverride, prefixes); err != nil {
+			return err
+		}
+		if !prefixes.Enabled() {
+			return nil
+		}
+		return checkBuildspecSecretEnv(aws.StringValue(buildInput.BatchBuild.BuildspecOverride), prefixes)
+	}
+	for _, build := range buildInput.Builds {
+		if err := checkSecretEnvVarList(build.EnvironmentVariablesOverride, prefixes); err != nil {
+			return err
+		}
+		if !prefixes.Enabled() {
+			continue
+		}
+		if err := checkBuildspecSecretEnv(aws.StringValue(build.BuildspecOverride), prefixes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBuildspecSecretEnv returns an error if env.parameter-store or env.secrets-manager of the rendered buildspec
+// refer to parameters or secrets which aren't allowed.
+func checkBuildspecSecretEnv(buildspec string, prefixes config.SecretPrefixes) error {
+	rendered := secretEnv{}
+	if err := yaml.Unmarshal([]byte(buildspec), &rendered); err != nil {
+		return fmt.Errorf("parse the rendered buildspec: %w", err)
+	}
+	for _, a := range []struct {
+		key     string
+		vars    map[string]string
+		allowed []string
+	}{
+		{key: "env.parameter-store", vars: rendered.Env.ParameterStore, allowed: prefixes.ParameterStore},
+		{key: "env.secrets-manager", vars: rendered.Env.SecretsManager, allowed: prefixes.SecretsManager},
+	} {
+		names := make([]string, 0, len(a.vars))
+		for name := range a.vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if value := a.vars[name]; !hasAnyPrefix(value, a.allowed) {
+				return fmt.Errorf("the environment variable %s of the buildspec's %s refers to a value which isn't allowed by allowed-secret-prefixes: %s", name, a.key, value)
+			}
+		}
+	}
+	return nil
+}
+
+func checkSecretEnvVarList(envVars []*codebuild.EnvironmentVariable, prefixes config.SecretPrefixes) error {
+	for _, envVar := range envVars {
+		var allowed []string
+		switch aws.StringValue(envVar.Type) {
+		case bspec.EnvTypeParameterStore:
+			allowed = prefixes.ParameterStore
+		case bspec.EnvTypeSecretsManager:
+			allowed = prefixes.SecretsManager
+		default:
+			continue
+		}
+		value := aws.StringValue(envVar.Value)
+		if !hasAnyPrefix(value, allowed) {
+			return fmt.Errorf("the environment variable %s refers to %s which isn't allowed by allowed-secret-prefixes: %s", aws.StringValue(envVar.Name), aws.StringValue(envVar.Type), value)
+		}
+	}
+	return nil
+}
+
+// hasAnyPrefix returns true if s starts with any prefix at the path boundary.
+// The prefix "/foo" matches "/foo", "/foo/bar", and "/foo:1" but doesn't match "/foobar".
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if hasPathPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPathPrefix(s, prefix string) bool {
+	if prefix == "" || !strings.HasPrefix(s, prefix) {
+		return false
+	}
+	if len(s) == len(prefix) || strings.HasSuffix(prefix, "/") || strings.HasSuffix(prefix, ":") {
+		return true
+	}
+	// ":" separates the secret name and the JSON key of Secrets Manager and the parameter name and the version of Parameter Store
+	c := s[len(prefix)]
+	return c == '/' || c == ':'
+}
@@ -0,0 +1,204 @@
+package lambda
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+)
+
+func Test_checkSecretEnvVars(t *testing.T) {
+	t.Parallel()
+	prefixes := config.SecretPrefixes{
+		ParameterStore: []string{"/lambuild/foo/"},
+	}
+	data := []struct {
+		title      string
+		buildInput domain.BuildInput
+		// unconfigured means allowed-secret-prefixes isn't configured
+		unconfigured bool
+		isErr        bool
+	}{
+		{
+			title: "plaintext",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{
+					{
+						EnvironmentVariablesOverride: []*codebuild.EnvironmentVariable{
+							{Name: aws.String("FOO"), Value: aws.String("/lambuild/bar/token")},
+						},
+					},
+				},
+			},
+		},
+		{
+			title: "allowed",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{
+					{
+						EnvironmentVariablesOverride: []*codebuild.EnvironmentVariable{
+							{Name: aws.String("FOO"), Value: aws.String("/lambuild/foo/token"), Type: aws.String("PARAMETER_STORE")},
+						},
+					},
+				},
+			},
+		},
+		{
+			title: "prefix doesn't match",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{
+					{
+						EnvironmentVariablesOverride: []*codebuild.EnvironmentVariable{
+							{Name: aws.String("FOO"), Value: aws.String("/lambuild/bar/token"), Type: aws.String("PARAMETER_STORE")},
+						},
+					},
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "secrets manager isn't allowed",
+			buildInput: domain.BuildInput{
+				Batched: true,
+				BatchBuild: &codebuild.StartBuildBatchInput{
+					EnvironmentVariablesOverride: []*codebuild.EnvironmentVariable{
+						{Name: aws.String("FOO"), Value: aws.String("/lambuild/foo/token"), Type: aws.String("SECRETS_MANAGER")},
+					},
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "buildspec's parameter-store is allowed",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{
+					{
+						BuildspecOverride: aws.String("env:\n  parameter-store:\n    FOO: /lambuild/foo/token\n"),
+					},
+				},
+			},
+		},
+		{
+			title: "buildspec's parameter-store isn't allowed",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{
+					{
+						BuildspecOverride: aws.String("env:\n  parameter-store:\n    FOO: /lambuild/bar/token\n"),
+					},
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "batch buildspec's secrets-manager isn't allowed",
+			buildInput: domain.BuildInput{
+				Batched: true,
+				BatchBuild: &codebuild.StartBuildBatchInput{
+					BuildspecOverride: aws.String("env:\n  secrets-manager:\n    FOO: lambuild/foo/token:password\n"),
+				},
+			},
+			isErr: true,
+		},
+		{
+			title: "buildspec's parameter-store without allowed-secret-prefixes",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{
+					{
+						BuildspecOverride: aws.String("env:\n  parameter-store:\n    FOO: /lambuild/bar/token\n"),
+					},
+				},
+			},
+			unconfigured: true,
+		},
+		{
+			title: "PARAMETER_STORE without allowed-secret-prefixes",
+			buildInput: domain.BuildInput{
+				Builds: []*codebuild.StartBuildInput{
+					{
+						EnvironmentVariablesOverride: []*codebuild.EnvironmentVariable{
+							{Name: aws.String("FOO"), Value: aws.String("/lambuild/foo/token"), Type: aws.String("PARAMETER_STORE")},
+						},
+					},
+				},
+			},
+			unconfigured: true,
+			isErr:        true,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			p := prefixes
+			if d.unconfigured {
+				p = config.SecretPrefixes{}
+			}
+			err := checkSecretEnvVars(&d.buildInput, p)
+			if d.isErr {
+				if err == nil {
+					t.Fatal("error should be returned")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func Test_hasAnyPrefix(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title    string
+		s        string
+		prefixes []string
+		exp      bool
+	}{
+		{
+			title:    "prefix with a trailing slash",
+			s:        "/team-a/token",
+			prefixes: []string{"/team-a/"},
+			exp:      true,
+		},
+		{
+			title:    "prefix without a trailing slash",
+			s:        "/team-a/token",
+			prefixes: []string{"/team-a"},
+			exp:      true,
+		},
+		{
+			title:    "same value",
+			s:        "/team-a",
+			prefixes: []string{"/team-a"},
+			exp:      true,
+		},
+		{
+			title:    "json key of secrets manager",
+			s:        "team-a:password",
+			prefixes: []string{"team-a"},
+			exp:      true,
+		},
+		{
+			title:    "not path boundary",
+			s:        "/team-ab/token",
+			prefixes: []string{"/team-a"},
+		},
+		{
+			title:    "empty prefix",
+			s:        "/team-a/token",
+			prefixes: []string{""},
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			if f := hasAnyPrefix(d.s, d.prefixes); f != d.exp {
+				t.Fatalf("wanted %v, got %v", d.exp, f)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Package lazy provides values which are loaded when they are needed for the first time.
+package lazy
+
+import (
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Value is a value which is loaded lazily and cached.
+// Concurrent loads of the same Value are deduplicated, so the loader is called only once even if many goroutines get the value at the same time.
+// If the loader fails, the error is returned to all callers waiting for the load and the value isn't cached, so the next Get calls the loader again.
+// Value is safe to copy, and copies share the state.
+type Value[T any] struct {
+	state *state[T]
+}
+
+type state[T any] struct {
+	mutex  sync.RWMutex
+	group  singleflight.Group
+	value  T
+	loaded bool
+}
+
+// New returns a Value which isn't loaded yet.
+func New[T any]() Value[T] {
+	return Value[T]{
+		state: &state[T]{},
+	}
+}
+
+// NewWithValue returns a Value which has been loaded.
+func NewWithValue[T any](value T) Value[T] {
+	return Value[T]{
+		state: &state[T]{
+			value:  value,
+			loaded: true,
+		},
+	}
+}
+
+// Valid returns true if the Value was created by New or NewWithValue.
+// The zero Value isn't valid and mustn't be used.
+func (v Value[T]) Valid() bool {
+	return v.state != nil
+}
+
+// Peek returns the value and true if the value has been loaded.
+// Peek doesn't load the value.
+func (v Value[T]) Peek() (T, bool) {
+	v.state.mutex.RLock()
+	defer v.state.mutex.RUnlock()
+	return v.state.value, v.state.loaded
+}
+
+// Set sets the value.
+func (v Value[T]) Set(value T) {
+	v.state.mutex.Lock()
+	v.state.value = value
+	v.state.loaded = true
+	v.state.mutex.Unlock()
+}
+
+// Get returns the value. If the value hasn't been loaded yet, load is called and the result is cached.
+func (v Value[T]) Get(load func() (T, error)) (T, error) {
+	if value, ok := v.Peek(); ok {
+		return value, nil
+	}
+	// Value has only one key
+	a, err, _ := v.state.group.Do("", func() (interface{}, error) {
+		// the value may have been loaded while waiting for the lock
+		if value, ok := v.Peek(); ok {
+			return value, nil
+		}
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+		v.Set(value)
+		return value, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, err //nolint:wrapcheck
+	}
+	return a.(T), nil //nolint:forcetypeassert
+}
@@ -0,0 +1,98 @@
+package lazy_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/suzuki-shunsuke/lambuild/pkg/lazy"
+)
+
+func TestValue_Get(t *testing.T) {
+	t.Parallel()
+	value := lazy.New[int]()
+	if _, ok := value.Peek(); ok {
+		t.Fatal("value shouldn't be loaded")
+	}
+	var count int32
+	load := func() (int, error) {
+		atomic.AddInt32(&count, 1)
+		time.Sleep(10 * time.Millisecond) //nolint:gomnd
+		return 0, nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := value.Get(load)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if n != 0 {
+				t.Errorf("got %d, wanted 0", n)
+			}
+		}()
+	}
+	wg.Wait()
+	if count != 1 {
+		t.Fatalf("load should be called once: %d", count)
+	}
+	// zero value is cached too
+	if _, err := value.Get(load); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("loaded value should be cached: %d", count)
+	}
+}
+
+func TestValue_Get_error(t *testing.T) {
+	t.Parallel()
+	value := lazy.New[string]()
+	if _, err := value.Get(func() (string, error) {
+		return "", errors.New("failed")
+	}); err == nil {
+		t.Fatal("error should be returned")
+	}
+	if _, ok := value.Peek(); ok {
+		t.Fatal("error shouldn't be cached")
+	}
+	s, err := value.Get(func() (string, error) {
+		return "foo", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "foo" {
+		t.Fatalf("got %s, wanted foo", s)
+	}
+}
+
+func TestNewWithValue(t *testing.T) {
+	t.Parallel()
+	value := lazy.NewWithValue("foo")
+	s, err := value.Get(func() (string, error) {
+		t.Fatal("load shouldn't be called")
+		return "", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "foo" {
+		t.Fatalf("got %s, wanted foo", s)
+	}
+}
+
+func TestValue_Valid(t *testing.T) {
+	t.Parallel()
+	if !lazy.New[int]().Valid() {
+		t.Fatal("Value created by New should be valid")
+	}
+	if (lazy.Value[int]{}).Valid() {
+		t.Fatal("the zero Value shouldn't be valid")
+	}
+}
@@ -0,0 +1,83 @@
+package pathfilter
+
+import (
+	"fmt"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Patterns is a list of glob patterns of file paths.
+// `*` matches any sequence of characters except for `/`, and `**` matches any number of directories.
+// Please see https://github.com/bmatcuk/doublestar#patterns .
+type Patterns []string
+
+func (patterns *Patterns) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var arr []string
+	if err := unmarshal(&arr); err != nil {
+		return err
+	}
+	if err := Patterns(arr).Validate(); err != nil {
+		return err
+	}
+	*patterns = arr
+	return nil
+}
+
+// Validate returns an error if any pattern is invalid.
+func (patterns Patterns) Validate() error {
+	for _, pattern := range patterns {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("glob pattern is invalid: %s", pattern)
+		}
+	}
+	return nil
+}
+
+// match returns true if the file matches any pattern.
+// patterns must be validated in advance.
+func (patterns Patterns) match(file string) bool {
+	for _, pattern := range patterns {
+		if f, _ := doublestar.Match(pattern, file); f {
+			return true
+		}
+	}
+	return false
+}
+
+// Empty returns true if both paths and pathsIgnore are empty.
+func Empty(paths, pathsIgnore Patterns) bool {
+	return len(paths) == 0 && len(pathsIgnore) == 0
+}
+
+// Match returns true if any file matches paths and doesn't match pathsIgnore.
+// If paths is empty, files which don't match pathsIgnore are matched.
+// If both paths and pathsIgnore are empty, Match returns true.
+func Match(paths, pathsIgnore Patterns, files []string) bool {
+	if Empty(paths, pathsIgnore) {
+		return true
+	}
+	for _, file := range files {
+		if len(paths) != 0 && !paths.match(file) {
+			continue
+		}
+		if pathsIgnore.match(file) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// MatchAll returns true if files aren't empty and every file matches any pattern.
+// patterns must be validated in advance.
+func MatchAll(patterns Patterns, files []string) bool {
+	if len(files) == 0 {
+		return false
+	}
+	for _, file := range files {
+		if !patterns.match(file) {
+			return false
+		}
+	}
+	return true
+}
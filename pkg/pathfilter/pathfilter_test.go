@@ -0,0 +1,119 @@
+package pathfilter_test
+
+import (
+	"testing"
+
+	"github.com/suzuki-shunsuke/lambuild/pkg/pathfilter"
+	"gopkg.in/yaml.v2"
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title       string
+		paths       pathfilter.Patterns
+		pathsIgnore pathfilter.Patterns
+		files       []string
+		exp         bool
+	}{
+		{
+			title: "no filter",
+			exp:   true,
+		},
+		{
+			title: "paths",
+			paths: pathfilter.Patterns{"svc/a/**"},
+			files: []string{"README.md", "svc/a/foo/main.go"},
+			exp:   true,
+		},
+		{
+			title: "paths don't match",
+			paths: pathfilter.Patterns{"svc/a/**"},
+			files: []string{"README.md", "svc/b/main.go"},
+		},
+		{
+			title: "single star doesn't match directories",
+			paths: pathfilter.Patterns{"svc/*.go"},
+			files: []string{"svc/a/main.go"},
+		},
+		{
+			title:       "paths-ignore",
+			pathsIgnore: pathfilter.Patterns{"**/*.md"},
+			files:       []string{"README.md", "docs/foo.md"},
+		},
+		{
+			title:       "paths-ignore doesn't match some files",
+			pathsIgnore: pathfilter.Patterns{"**/*.md"},
+			files:       []string{"README.md", "main.go"},
+			exp:         true,
+		},
+		{
+			title:       "paths and paths-ignore",
+			paths:       pathfilter.Patterns{"svc/a/**"},
+			pathsIgnore: pathfilter.Patterns{"**/*.md"},
+			files:       []string{"svc/a/README.md", "svc/b/main.go"},
+		},
+		{
+			title: "no changed file",
+			paths: pathfilter.Patterns{"**"},
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			if f := pathfilter.Match(d.paths, d.pathsIgnore, d.files); f != d.exp {
+				t.Fatalf("wanted %v, got %v", d.exp, f)
+			}
+		})
+	}
+}
+
+func TestMatchAll(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title    string
+		patterns pathfilter.Patterns
+		files    []string
+		exp      bool
+	}{
+		{
+			title:    "all files match",
+			patterns: pathfilter.Patterns{"docs/**", "*.md"},
+			files:    []string{"README.md", "docs/foo/index.html"},
+			exp:      true,
+		},
+		{
+			title:    "some files don't match",
+			patterns: pathfilter.Patterns{"docs/**"},
+			files:    []string{"README.md", "docs/foo/index.html"},
+		},
+		{
+			title:    "no changed file",
+			patterns: pathfilter.Patterns{"**"},
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			if f := pathfilter.MatchAll(d.patterns, d.files); f != d.exp {
+				t.Fatalf("wanted %v, got %v", d.exp, f)
+			}
+		})
+	}
+}
+
+func TestPatterns_UnmarshalYAML(t *testing.T) {
+	t.Parallel()
+	patterns := pathfilter.Patterns{}
+	if err := yaml.Unmarshal([]byte(`["svc/**/*.go"]`), &patterns); err != nil {
+		t.Fatal(err)
+	}
+	if len(patterns) != 1 {
+		t.Fatalf("the number of patterns must be 1: %d", len(patterns))
+	}
+	if err := yaml.Unmarshal([]byte(`["svc/[a"]`), &patterns); err == nil {
+		t.Fatal("invalid pattern must be rejected")
+	}
+}
@@ -0,0 +1,266 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/sirupsen/logrus"
+	bspec "github.com/suzuki-shunsuke/lambuild/pkg/buildspec"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/expr"
+	"github.com/suzuki-shunsuke/lambuild/pkg/pathfilter"
+	"github.com/suzuki-shunsuke/lambuild/pkg/template"
+	"github.com/suzuki-shunsuke/lambuild/pkg/yamlutil"
+)
+
+const draft = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a JSON Schema.
+// https://json-schema.org/
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	Ref         string             `json:"$ref,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	// AdditionalProperties is either bool or *Schema.
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+func boolExpression() *Schema {
+	return &Schema{
+		Type:        "string",
+		Description: "bool expression. https://github.com/suzuki-shunsuke/lambuild/blob/main/docs/expression.md",
+	}
+}
+
+func stringExpression() *Schema {
+	return &Schema{
+		Type:        "string",
+		Description: "string expression. https://github.com/suzuki-shunsuke/lambuild/blob/main/docs/expression.md",
+	}
+}
+
+func logLevels() []string {
+	levels := make([]string, 0, len(logrus.AllLevels)+1)
+	for _, lvl := range logrus.AllLevels {
+		levels = append(levels, lvl.String())
+	}
+	// logrus.ParseLevel accepts "warn" too
+	return append(levels, "warn")
+}
+
+// customTypes are schemas of types which implement UnmarshalYAML.
+// The schemas must be consistent with UnmarshalYAML.
+func customTypes() map[reflect.Type]*Schema {
+	return map[reflect.Type]*Schema{
+		reflect.TypeOf(expr.Bool{}):   boolExpression(),
+		reflect.TypeOf(expr.String{}): stringExpression(),
+		reflect.TypeOf(template.Template{}): {
+			Type:        "string",
+			Description: "Go's text/template with sprig functions",
+		},
+		reflect.TypeOf(template.TrustedTemplate{}): {
+			Type:        "string",
+			Description: "Go's text/template with sprig functions",
+		},
+		reflect.TypeOf(config.LogLevel{}): {
+			Type: "string",
+			Enum: logLevels(),
+		},
+		reflect.TypeOf(config.Permission{}): {
+			Type: "string",
+			Enum: config.PermissionLevels(),
+		},
+		reflect.TypeOf(config.Regexp{}): {
+			Type:        "string",
+			Format:      "regex",
+			Description: "Go's regular expression. https://pkg.go.dev/regexp/syntax",
+		},
+		reflect.TypeOf(config.ForkPolicy{}): {
+			Type: "string",
+			Enum: config.ForkPolicies(),
+		},
+		reflect.TypeOf(pathfilter.Patterns{}): {
+			Type:        "array",
+			Items:       &Schema{Type: "string"},
+			Description: "glob patterns of file paths. https://github.com/bmatcuk/doublestar#patterns",
+		},
+		reflect.TypeOf(bspec.Command{}): {
+			OneOf: []*Schema{
+				{
+					Type: "string",
+				},
+				{
+					Type: "object",
+					Properties: map[string]*Schema{
+						"command": {Type: "string"},
+						"if":      boolExpression(),
+					},
+					AdditionalProperties: false,
+				},
+			},
+		},
+		reflect.TypeOf(bspec.EnvVar{}): {
+			OneOf: []*Schema{
+				stringExpression(),
+				{
+					Type: "object",
+					Properties: map[string]*Schema{
+						"value": stringExpression(),
+						"type": {
+							Type: "string",
+							Enum: bspec.EnvTypes(),
+						},
+					},
+					Required:             []string{"value"},
+					AdditionalProperties: false,
+				},
+			},
+		},
+		reflect.TypeOf(bspec.ExprList{}): {
+			Type: "array",
+			Items: &Schema{
+				OneOf: []*Schema{
+					{
+						Type: "string",
+					},
+					{
+						Type: "object",
+						Properties: map[string]*Schema{
+							"value": {Type: "string"},
+							"if":    boolExpression(),
+						},
+						AdditionalProperties: false,
+					},
+				},
+			},
+		},
+	}
+}
+
+// inlineKeys are keys which are allowed in the inline map of the type.
+// The value of these keys are passed to CodeBuild as they are, so the schema of them isn't defined.
+var inlineKeys = map[reflect.Type][]string{ //nolint:gochecknoglobals
+	reflect.TypeOf(bspec.Buildspec{}): bspec.CodeBuildKeys,
+	reflect.TypeOf(bspec.Phase{}):     bspec.PhaseCodeBuildKeys,
+}
+
+// Buildspec returns the JSON Schema of lambuild.yaml.
+func Buildspec() (*Schema, error) {
+	return generate("lambuild.yaml", reflect.TypeOf(bspec.Buildspec{}))
+}
+
+// Config returns the JSON Schema of the Lambda Function's configuration.
+func Config() (*Schema, error) {
+	return generate("lambuild configuration", reflect.TypeOf(config.Config{}))
+}
+
+func generate(title string, typ reflect.Type) (*Schema, error) {
+	gen := &generator{
+		customTypes: customTypes(),
+		definitions: map[string]*Schema{},
+		names:       map[string]reflect.Type{},
+	}
+	root, err := gen.generate(typ)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{
+		Schema:      draft,
+		Title:       title,
+		Ref:         root.Ref,
+		Definitions: gen.definitions,
+	}, nil
+}
+
+type generator struct {
+	customTypes map[reflect.Type]*Schema
+	definitions map[string]*Schema
+	// names is used to detect the conflict of definition names
+	names map[string]reflect.Type
+}
+
+func (gen *generator) generate(typ reflect.Type) (*Schema, error) {
+	if s, ok := gen.customTypes[typ]; ok {
+		return s, nil
+	}
+	switch typ.Kind() { //nolint:exhaustive
+	case reflect.Ptr:
+		return gen.generate(typ.Elem())
+	case reflect.Struct:
+		return gen.generateStruct(typ)
+	case reflect.Map:
+		elem, err := gen.generate(typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{
+			Type:                 "object",
+			AdditionalProperties: elem,
+		}, nil
+	case reflect.Slice, reflect.Array:
+		elem, err := gen.generate(typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{
+			Type:  "array",
+			Items: elem,
+		}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type: %s", typ)
+	}
+}
+
+// generateStruct adds the schema of the struct to definitions and returns the reference to it.
+func (gen *generator) generateStruct(typ reflect.Type) (*Schema, error) {
+	name := typ.Name()
+	ref := &Schema{Ref: "#/definitions/" + name}
+	if t, ok := gen.names[name]; ok {
+		if t != typ {
+			return nil, fmt.Errorf("definition name conflicts: %s and %s", t, typ)
+		}
+		return ref, nil
+	}
+	gen.names[name] = typ
+	s := &Schema{
+		Type:                 "object",
+		Properties:           map[string]*Schema{},
+		AdditionalProperties: false,
+	}
+	// register the definition before properties are generated for recursive types
+	gen.definitions[name] = s
+	fields, _ := yamlutil.Fields(typ)
+	for _, field := range fields {
+		prop, err := gen.generate(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("generate the schema of %s.%s: %w", name, field.Key, err)
+		}
+		s.Properties[field.Key] = prop
+	}
+	for _, key := range inlineKeys[typ] {
+		s.Properties[key] = &Schema{
+			Description: "passed to CodeBuild as it is",
+		}
+	}
+	return ref, nil
+}
@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	bspec "github.com/suzuki-shunsuke/lambuild/pkg/buildspec"
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/yamlutil"
+	"gopkg.in/yaml.v2"
+)
+
+var unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem() //nolint:gochecknoglobals
+
+// unmarshalers adds types which implement yaml.Unmarshaler in typ to types.
+func unmarshalers(typ reflect.Type, types map[reflect.Type]struct{}, visited map[reflect.Type]struct{}) {
+	if _, ok := visited[typ]; ok {
+		return
+	}
+	visited[typ] = struct{}{}
+	if typ.Kind() != reflect.Ptr && reflect.PtrTo(typ).Implements(unmarshalerType) {
+		types[typ] = struct{}{}
+		return
+	}
+	switch typ.Kind() { //nolint:exhaustive
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		unmarshalers(typ.Elem(), types, visited)
+	case reflect.Struct:
+		fields, _ := yamlutil.Fields(typ)
+		for _, field := range fields {
+			unmarshalers(field.Type, types, visited)
+		}
+	}
+}
+
+func Test_customTypes(t *testing.T) {
+	t.Parallel()
+	types := map[reflect.Type]struct{}{}
+	visited := map[reflect.Type]struct{}{}
+	unmarshalers(reflect.TypeOf(config.Config{}), types, visited)
+	unmarshalers(reflect.TypeOf(bspec.Buildspec{}), types, visited)
+	if len(types) == 0 {
+		t.Fatal("no type implements yaml.Unmarshaler")
+	}
+	custom := customTypes()
+	for typ := range types {
+		if _, ok := custom[typ]; !ok {
+			t.Errorf("%s implements yaml.Unmarshaler but the schema isn't defined in customTypes", typ)
+		}
+	}
+}
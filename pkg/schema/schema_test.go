@@ -0,0 +1,290 @@
+package schema_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/suzuki-shunsuke/lambuild/pkg/config"
+	"github.com/suzuki-shunsuke/lambuild/pkg/schema"
+	yamlv2 "gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+)
+
+func TestBuildspec(t *testing.T) {
+	t.Parallel()
+	s, err := schema.Buildspec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Ref != "#/definitions/Buildspec" {
+		t.Fatalf("$ref is wrong: %s", s.Ref)
+	}
+	buildspec, ok := s.Definitions["Buildspec"]
+	if !ok {
+		t.Fatal("Buildspec must be defined")
+	}
+	for _, key := range []string{"version", "lambuild", "batch", "phases", "env"} {
+		if _, ok := buildspec.Properties[key]; !ok {
+			t.Fatalf("Buildspec must have the property %s", key)
+		}
+	}
+	if buildspec.AdditionalProperties != false {
+		t.Fatal("Buildspec must not allow additional properties")
+	}
+	phase, ok := s.Definitions["Phase"]
+	if !ok {
+		t.Fatal("Phase must be defined")
+	}
+	if n := len(phase.Properties["commands"].Items.OneOf); n != 2 { //nolint:gomnd
+		t.Fatalf("command must be either string or map: %d", n)
+	}
+	dynamic, ok := s.Definitions["MatrixDynamic"]
+	if !ok {
+		t.Fatal("MatrixDynamic must be defined")
+	}
+	if dynamic.Properties["buildspec"].Type != "array" {
+		t.Fatalf("build-matrix.dynamic.buildspec must be array: %s", dynamic.Properties["buildspec"].Type)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+	s, err := schema.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hook, ok := s.Definitions["Hook"]
+	if !ok {
+		t.Fatal("Hook must be defined")
+	}
+	for _, key := range []string{"if", "config", "service-role", "project-name", "assume-role-arn"} {
+		if _, ok := hook.Properties[key]; !ok {
+			t.Fatalf("Hook must have the property %s", key)
+		}
+	}
+	if typ := s.Definitions["Config"].Properties["build-status-context"].Type; typ != "string" {
+		t.Fatalf("build-status-context must be string: %s", typ)
+	}
+	permission := s.Definitions["IssueComment"].Properties["permission"]
+	if len(permission.Enum) != 4 { //nolint:gomnd
+		t.Fatalf("permission must be enum: %v", permission.Enum)
+	}
+	if typ := s.Definitions["Repository"].Properties["name-regexp"].Type; typ != "string" {
+		t.Fatalf("name-regexp must be string: %s", typ)
+	}
+	policy := s.Definitions["ForkPullRequest"].Properties["policy"]
+	if len(policy.Enum) != 5 { //nolint:gomnd
+		t.Fatalf("fork pull request policy must be enum: %v", policy.Enum)
+	}
+}
+
+// validate validates the value with the subset of JSON Schema which schema.Buildspec and schema.Config generate.
+func validate(root, s *schema.Schema, value interface{}, path string) error {
+	if s.Ref != "" {
+		def, ok := root.Definitions[strings.TrimPrefix(s.Ref, "#/definitions/")]
+		if !ok {
+			return fmt.Errorf("%s: definition isn't found: %s", path, s.Ref)
+		}
+		return validate(root, def, value, path)
+	}
+	if len(s.OneOf) != 0 {
+		matched := 0
+		for _, sub := range s.OneOf {
+			if validate(root, sub, value, path) == nil {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return fmt.Errorf("%s: %d schemas of oneOf match", path, matched)
+		}
+		return nil
+	}
+	if err := validateType(s.Type, value); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if len(s.Enum) != 0 {
+		str, _ := value.(string)
+		found := false
+		for _, e := range s.Enum {
+			if e == str {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %v isn't one of %v", path, value, s.Enum)
+		}
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return validateObject(root, s, v, path)
+	case []interface{}:
+		if s.Items == nil {
+			return nil
+		}
+		for i, elem := range v {
+			if err := validate(root, s.Items, elem, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateType(typ string, value interface{}) error {
+	ok := true
+	switch typ {
+	case "":
+	case "string":
+		_, ok = value.(string)
+	case "boolean":
+		_, ok = value.(bool)
+	case "integer":
+		_, ok = value.(int)
+	case "number":
+		switch value.(type) {
+		case int, float64:
+		default:
+			ok = false
+		}
+	case "object":
+		_, ok = value.(map[string]interface{})
+	case "array":
+		_, ok = value.([]interface{})
+	default:
+		return fmt.Errorf("unsupported type: %s", typ)
+	}
+	if !ok {
+		return fmt.Errorf("%v must be %s", value, typ)
+	}
+	return nil
+}
+
+func validateObject(root, s *schema.Schema, obj map[string]interface{}, path string) error {
+	for _, key := range s.Required {
+		if _, ok := obj[key]; !ok {
+			return fmt.Errorf("%s: %s is required", path, key)
+		}
+	}
+	for key, val := range obj {
+		prop, ok := s.Properties[key]
+		if !ok {
+			switch additional := s.AdditionalProperties.(type) {
+			case bool:
+				if !additional {
+					return fmt.Errorf("%s: additional property isn't allowed: %s", path, key)
+				}
+				continue
+			case *schema.Schema:
+				prop = additional
+			default:
+				continue
+			}
+		}
+		if err := validate(root, prop, val, path+"."+key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestConfig_validate(t *testing.T) {
+	t.Parallel()
+	s, err := schema.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgYAML := `
+region: us-east-1
+log-level: debug
+ssm-parameter:
+  parameter-name:
+    github-token: lambuild_github_token
+    webhook-secret: lambuild_webhook_secret
+build-status-context: "AWS Codebuild ({{.event.Headers.Event}})"
+error-notification-template: |
+  lambuild failed to procceed the request.
+repositories:
+- name-regexp: "^suzuki-shunsuke/test-"
+  auto-cancel: true
+  fork-pull-request:
+    policy: require-label
+    label: ok-to-test
+  authorization:
+    permission: write
+    teams:
+    - suzuki-shunsuke/maintainers
+    denied-comment: "@{{.user}} isn't allowed to run builds"
+  issue-comment:
+    permission: admin
+  allowed-secret-prefixes:
+    parameter-store:
+    - /lambuild/
+  hooks:
+  - config: lambuild.yaml
+    if: 'event.Headers.Event == "pull_request"'
+    paths:
+    - "src/**"
+    allowed-overrides:
+      artifact-locations:
+      - lambuild-artifacts
+  codebuild:
+    project-name: test-lambuild
+organizations:
+- name: suzuki-shunsuke
+  codebuild:
+    project-name: test-lambuild
+`
+	var cfg config.Config
+	if err := yamlv2.Unmarshal([]byte(cfgYAML), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(cfgYAML), &value); err != nil {
+		t.Fatal(err)
+	}
+	if err := validate(s, s, value, "$"); err != nil {
+		t.Fatal(err)
+	}
+	if err := validate(s, s, map[string]interface{}{"region": 1}, "$"); err == nil {
+		t.Fatal("invalid configuration must be rejected")
+	}
+}
+
+func TestBuildspec_validate(t *testing.T) {
+	t.Parallel()
+	s, err := schema.Buildspec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buildspecYAML := `
+version: 0.2
+lambuild:
+  if: 'event.Headers.Event == "pull_request"'
+  build-status-context: "foo ({{.event.Headers.Event}})"
+  image: aws/codebuild/standard:5.0
+  env:
+    variables:
+      NAME: "item.name"
+  items:
+  - image: aws/codebuild/standard:5.0
+    param:
+      name: foo
+env:
+  variables:
+    FOO: foo
+phases:
+  build:
+    commands:
+    - "echo foo"
+    - command: "echo bar"
+      if: "true"
+`
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(buildspecYAML), &value); err != nil {
+		t.Fatal(err)
+	}
+	if err := validate(s, s, value, "$"); err != nil {
+		t.Fatal(err)
+	}
+}
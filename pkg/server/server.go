@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/sirupsen/logrus"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+)
+
+const (
+	// maxPayloadSize is the maximum size of the webhook payload. GitHub caps payloads at 25 MB.
+	maxPayloadSize    = 25 * 1024 * 1024
+	shutdownTimeout   = 30 * time.Second
+	readHeaderTimeout = 10 * time.Second
+	// handleTimeout is the timeout of handling a webhook event.
+	// GitHub drops the connection if the response doesn't return in 10 seconds,
+	// so the event is handled with a context which is detached from the request.
+	handleTimeout = 10 * time.Minute
+)
+
+// Handler handles webhook events. *lambda.Handler implements Handler.
+type Handler interface {
+	Do(ctx context.Context, event domain.Event) (events.APIGatewayV2HTTPResponse, error)
+}
+
+type Param struct {
+	// Addr is the TCP address for the server to listen on. e.g. ":8080"
+	Addr string
+	// WebhookPath is the path of the webhook endpoint. e.g. "/lambuild"
+	WebhookPath string
+}
+
+// NewHTTPHandler returns a http.Handler which has the webhook endpoint and the health check endpoint "/health".
+func NewHTTPHandler(handler Handler, webhookPath string) http.Handler {
+	return newHTTPHandler(handler, webhookPath, &sync.WaitGroup{})
+}
+
+// newHTTPHandler returns a http.Handler whose webhook events are tracked by wg.
+func newHTTPHandler(handler Handler, webhookPath string, wg *sync.WaitGroup) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "ok")
+	})
+	mux.Handle(webhookPath, &webhookHandler{handler: handler, wg: wg})
+	return mux
+}
+
+// webhookHandler translates a HTTP request into domain.Event,
+// so the signature check and hook logic of the Lambda Function are reused.
+// wg tracks webhook events which are being handled,
+// because they are handled even after the connection is closed.
+type webhookHandler struct {
+	handler Handler
+	wg      *sync.WaitGroup
+}
+
+func (wh *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+	if err != nil {
+		logrus.WithError(err).Warn("read a request body")
+		http.Error(w, "failed to read a request body", http.StatusBadRequest)
+		return
+	}
+	event := domain.Event{
+		Body: string(body),
+		Headers: domain.Headers{
+			Event:     r.Header.Get("X-GitHub-Event"),
+			Delivery:  r.Header.Get("X-GitHub-Delivery"),
+			Signature: r.Header.Get("X-Hub-Signature-256"),
+		},
+	}
+	// The request's context is canceled when GitHub drops the connection.
+	// If builds are started with the context, some builds are started and others aren't, so the detached context is used.
+	ctx, cancel := context.WithTimeout(context.Background(), handleTimeout)
+	defer cancel()
+	wh.wg.Add(1)
+	defer wh.wg.Done()
+	resp, err := wh.handler.Do(ctx, event)
+	if err != nil {
+		logrus.WithError(err).Error("handle a webhook event")
+		http.Error(w, "failed to handle a webhook event", http.StatusInternalServerError)
+		return
+	}
+	for k, v := range resp.Headers {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(resp.StatusCode)
+	if _, err := fmt.Fprint(w, resp.Body); err != nil {
+		logrus.WithError(err).Warn("write a response body")
+	}
+}
+
+// Run starts the HTTP server and shuts it down gracefully when ctx is canceled.
+// Webhook events which are being handled are waited for up to handleTimeout.
+func Run(ctx context.Context, handler Handler, param Param) error {
+	wg := &sync.WaitGroup{}
+	srv := &http.Server{
+		Addr:              param.Addr,
+		Handler:           newHTTPHandler(handler, param.WebhookPath, wg),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		logrus.WithFields(logrus.Fields{
+			"addr":         param.Addr,
+			"webhook_path": param.WebhookPath,
+		}).Info("start the HTTP server")
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("run the HTTP server: %w", err)
+	case <-ctx.Done():
+	}
+
+	logrus.Info("shut down the HTTP server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	// Shutdown times out if webhook events are being handled, and they are waited for below.
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("shut down the HTTP server: %w", err)
+	}
+	if !waitTimeout(wg, handleTimeout) {
+		return errors.New("webhook events are still being handled after the timeout")
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("run the HTTP server: %w", err)
+	}
+	return nil
+}
+
+// waitTimeout waits for wg up to timeout.
+// If wg isn't done in timeout, waitTimeout returns false.
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
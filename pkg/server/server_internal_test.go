@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+)
+
+type blockingHandler struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *blockingHandler) Do(ctx context.Context, event domain.Event) (events.APIGatewayV2HTTPResponse, error) {
+	close(h.started)
+	<-h.release
+	return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusAccepted}, nil
+}
+
+func Test_newHTTPHandler_wait(t *testing.T) {
+	t.Parallel()
+	h := &blockingHandler{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	wg := &sync.WaitGroup{}
+	httpHandler := newHTTPHandler(h, "/lambuild", wg)
+	go httpHandler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/lambuild", strings.NewReader(`{}`)))
+	<-h.started
+	if waitTimeout(wg, 10*time.Millisecond) {
+		t.Fatal("the event being handled should be waited for")
+	}
+	close(h.release)
+	if !waitTimeout(wg, time.Minute) {
+		t.Fatal("the handled event should be done")
+	}
+}
+
+func Test_waitTimeout(t *testing.T) {
+	t.Parallel()
+	wg := &sync.WaitGroup{}
+	if !waitTimeout(wg, time.Second) {
+		t.Fatal("waitTimeout should return true if no event is handled")
+	}
+	wg.Add(1)
+	if waitTimeout(wg, 10*time.Millisecond) {
+		t.Fatal("waitTimeout should return false if the event is still handled")
+	}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		wg.Done()
+	}()
+	if !waitTimeout(wg, time.Minute) {
+		t.Fatal("waitTimeout should wait for the event")
+	}
+}
@@ -0,0 +1,129 @@
+package server_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/suzuki-shunsuke/lambuild/pkg/domain"
+	"github.com/suzuki-shunsuke/lambuild/pkg/server"
+)
+
+type handler struct {
+	event domain.Event
+	// ctxErr is the error of the context after the request is canceled
+	ctxErr error
+	// requestCanceled is closed when the request is canceled
+	requestCanceled chan struct{}
+}
+
+func (h *handler) Do(ctx context.Context, event domain.Event) (events.APIGatewayV2HTTPResponse, error) {
+	h.event = event
+	if h.requestCanceled != nil {
+		<-h.requestCanceled
+		h.ctxErr = ctx.Err()
+	}
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusAccepted,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Body: `{"message":"ignored"}`,
+	}, nil
+}
+
+func TestNewHTTPHandler(t *testing.T) {
+	t.Parallel()
+	h := &handler{}
+	srv := httptest.NewServer(server.NewHTTPHandler(h, "/lambuild"))
+	defer srv.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL+"/lambuild", strings.NewReader(`{"zen":"foo"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-GitHub-Event", "ping")
+	req.Header.Set("X-GitHub-Delivery", "0000")
+	req.Header.Set("X-Hub-Signature-256", "sha256=0000")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("got %d, wanted %d", resp.StatusCode, http.StatusAccepted)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"message":"ignored"}` {
+		t.Fatalf("got %s", string(b))
+	}
+	exp := domain.Event{
+		Body: `{"zen":"foo"}`,
+		Headers: domain.Headers{
+			Event:     "ping",
+			Delivery:  "0000",
+			Signature: "sha256=0000",
+		},
+	}
+	if diff := cmp.Diff(exp, h.event); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestNewHTTPHandler_detachedContext(t *testing.T) {
+	t.Parallel()
+	h := &handler{
+		requestCanceled: make(chan struct{}),
+	}
+	httpHandler := server.NewHTTPHandler(h, "/lambuild")
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodPost, "/lambuild", strings.NewReader(`{"zen":"foo"}`)).WithContext(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		httpHandler.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+	// GitHub drops the connection while the event is handled
+	cancel()
+	close(h.requestCanceled)
+	<-done
+	if h.ctxErr != nil {
+		t.Fatalf("the context of the event handling shouldn't be canceled: %v", h.ctxErr)
+	}
+}
+
+func TestNewHTTPHandler_health(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(server.NewHTTPHandler(&handler{}, "/lambuild"))
+	defer srv.Close()
+
+	for _, d := range []struct {
+		method string
+		path   string
+		exp    int
+	}{
+		{method: http.MethodGet, path: "/health", exp: http.StatusOK},
+		{method: http.MethodGet, path: "/lambuild", exp: http.StatusMethodNotAllowed},
+	} {
+		req, err := http.NewRequestWithContext(context.Background(), d.method, srv.URL+d.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != d.exp {
+			t.Fatalf("%s %s: got %d, wanted %d", d.method, d.path, resp.StatusCode, d.exp)
+		}
+	}
+}
@@ -2,13 +2,80 @@ package template
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"text/template"
+	"time"
 
 	"github.com/Masterminds/sprig/v3"
 )
 
+const (
+	// maxOutputSize is the maximum byte size of a rendered template.
+	maxOutputSize = 64 * 1024
+	// executionTimeout is the duration after which Execute stops waiting for the rendering.
+	// It isn't a hard timeout. Please see Template.Execute.
+	executionTimeout = 3 * time.Second
+)
+
+var (
+	ErrOutputTooLarge = errors.New("the rendered template is too large")
+	ErrTimeout        = errors.New("rendering a template timed out")
+)
+
+// safeFunctions are sprig functions which can be used in templates of repositories.
+// Functions which read the Lambda Function's environment variables, access the network or filesystem,
+// mutate the template parameter, or take unbounded memory or CPU aren't included.
+var safeFunctions = []string{ //nolint:gochecknoglobals
+	// date
+	"ago", "date", "dateInZone", "dateModify", "duration", "durationRound", "htmlDate", "htmlDateInZone",
+	"mustDateModify", "mustToDate", "now", "toDate", "unixEpoch",
+	// string
+	"abbrev", "abbrevboth", "trunc", "trim", "upper", "lower", "title", "untitle", "substr",
+	"trimall", "trimAll", "trimSuffix", "trimPrefix", "nospace", "initials", "swapcase",
+	"snakecase", "camelcase", "kebabcase", "wrap", "wrapWith", "contains", "hasPrefix", "hasSuffix",
+	"quote", "squote", "cat", "indent", "nindent", "replace", "plural",
+	"split", "splitList", "splitn", "toStrings", "join", "sortAlpha",
+	// regular expression
+	"regexMatch", "mustRegexMatch", "regexFindAll", "mustRegexFindAll", "regexFind", "mustRegexFind",
+	"regexReplaceAll", "mustRegexReplaceAll", "regexReplaceAllLiteral", "mustRegexReplaceAllLiteral",
+	"regexSplit", "mustRegexSplit", "regexQuoteMeta",
+	// hash and encoding
+	"sha1sum", "sha256sum", "adler32sum", "b64enc", "b64dec", "b32enc", "b32dec",
+	"fromJson", "toJson", "toPrettyJson", "toRawJson", "mustFromJson", "mustToJson", "mustToPrettyJson", "mustToRawJson",
+	// type conversion and math
+	"toString", "atoi", "int64", "int", "float64", "toDecimal",
+	"add1", "add", "sub", "div", "mod", "mul", "add1f", "addf", "subf", "divf", "mulf",
+	"biggest", "max", "min", "maxf", "minf", "ceil", "floor", "round",
+	// flow control and reflection
+	"default", "empty", "coalesce", "all", "any", "ternary", "fail",
+	"typeOf", "typeIs", "typeIsLike", "kindOf", "kindIs", "deepEqual", "deepCopy", "mustDeepCopy",
+	// path
+	"base", "dir", "clean", "ext", "isAbs",
+	// list and dict
+	"tuple", "list", "dict", "get", "hasKey", "pluck", "keys", "pick", "omit", "values", "dig",
+	"append", "push", "mustAppend", "mustPush", "prepend", "mustPrepend",
+	"first", "mustFirst", "rest", "mustRest", "last", "mustLast", "initial", "mustInitial",
+	"reverse", "mustReverse", "uniq", "mustUniq", "without", "mustWithout", "has", "mustHas",
+	"slice", "mustSlice", "concat", "compact", "mustCompact",
+	// others
+	"semver", "semverCompare", "urlParse", "urlJoin",
+}
+
+// safeFuncMap returns sprig functions which can be used in templates of repositories.
+func safeFuncMap() template.FuncMap {
+	all := sprig.TxtFuncMap()
+	funcs := make(template.FuncMap, len(safeFunctions))
+	for _, name := range safeFunctions {
+		funcs[name] = all[name]
+	}
+	return funcs
+}
+
+// Template is a template in repositories.
+// Only a curated set of sprig functions can be used, and the output size and execution time are limited.
 type Template struct {
 	template *template.Template
 }
@@ -22,7 +89,7 @@ func (tpl *Template) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if err := unmarshal(&a); err != nil {
 		return err
 	}
-	t, err := compile(a)
+	t, err := compile(a, safeFuncMap())
 	if err != nil {
 		return err
 	}
@@ -30,21 +97,37 @@ func (tpl *Template) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+// Execute renders the template.
+// If the rendering doesn't finish in executionTimeout, Execute returns ErrTimeout without waiting for it.
+// text/template can't be canceled, so the rendering goroutine is stopped only when it writes the output next time.
+// A template which loops without writing keeps running in the background until the loop ends.
 func (tpl *Template) Execute(param interface{}) (string, error) {
 	if tpl.template == nil {
 		return "", nil
 	}
-	buf := &bytes.Buffer{}
-	if err := tpl.template.Execute(buf, param); err != nil {
-		return "", fmt.Errorf("render a template: %w", err)
+	w := &limitedWriter{limit: maxOutputSize}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- tpl.template.Execute(w, param)
+	}()
+	timer := time.NewTimer(executionTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return "", fmt.Errorf("render a template: %w", err)
+		}
+		return w.buf.String(), nil
+	case <-timer.C:
+		w.cancel()
+		return "", ErrTimeout
 	}
-	return buf.String(), nil
 }
 
 func New(s string) (Template, error) {
-	tpl, err := template.New("_").Funcs(sprig.TxtFuncMap()).Parse(s)
+	tpl, err := compile(s, safeFuncMap())
 	if err != nil {
-		return Template{}, fmt.Errorf("parse a template: %w", err)
+		return Template{}, err
 	}
 	return Template{template: tpl}, nil
 }
@@ -58,10 +141,96 @@ func NewForTest(t *testing.T, s string) Template {
 	return a
 }
 
-func compile(s string) (*template.Template, error) {
-	tpl, err := template.New("_").Funcs(sprig.TxtFuncMap()).Parse(s)
+func compile(s string, funcs template.FuncMap) (*template.Template, error) {
+	tpl, err := template.New("_").Funcs(funcs).Parse(s)
 	if err != nil {
 		return nil, fmt.Errorf("parse a template: %w", err)
 	}
 	return tpl, nil
 }
+
+// TrustedTemplate is a template in the Lambda Function's configuration.
+// The template is compiled by Compile after the configuration is read,
+// because whether all sprig functions can be used depends on the configuration.
+type TrustedTemplate struct {
+	text     string
+	template Template
+}
+
+func (tpl *TrustedTemplate) Empty() bool {
+	return tpl.text == ""
+}
+
+func (tpl *TrustedTemplate) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var a string
+	if err := unmarshal(&a); err != nil {
+		return err
+	}
+	t, err := NewTrusted(a)
+	if err != nil {
+		return err
+	}
+	*tpl = t
+	return nil
+}
+
+// NewTrusted returns a template which isn't compiled yet.
+// The syntax of the template is validated.
+func NewTrusted(s string) (TrustedTemplate, error) {
+	if _, err := compile(s, sprig.TxtFuncMap()); err != nil {
+		return TrustedTemplate{}, err
+	}
+	return TrustedTemplate{text: s}, nil
+}
+
+// Compile compiles the template.
+// If allFunctions is true, all sprig functions including env and expandenv can be used.
+// Otherwise, only the same functions as templates of repositories can be used.
+func (tpl *TrustedTemplate) Compile(allFunctions bool) error {
+	if tpl.text == "" {
+		return nil
+	}
+	funcs := safeFuncMap()
+	if allFunctions {
+		funcs = sprig.TxtFuncMap()
+	}
+	t, err := compile(tpl.text, funcs)
+	if err != nil {
+		return err
+	}
+	tpl.template = Template{template: t}
+	return nil
+}
+
+// Template returns the compiled template.
+func (tpl *TrustedTemplate) Template() Template {
+	return tpl.template
+}
+
+func (tpl *TrustedTemplate) Execute(param interface{}) (string, error) {
+	if tpl.text != "" && tpl.template.Empty() {
+		return "", errors.New("the template isn't compiled")
+	}
+	return tpl.template.Execute(param)
+}
+
+// limitedWriter is a writer whose size is limited and which can be canceled.
+type limitedWriter struct {
+	buf      bytes.Buffer
+	limit    int
+	canceled int32
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if atomic.LoadInt32(&w.canceled) == 1 {
+		return 0, ErrTimeout
+	}
+	if w.buf.Len()+len(p) > w.limit {
+		return 0, ErrOutputTooLarge
+	}
+	return w.buf.Write(p) //nolint:wrapcheck
+}
+
+func (w *limitedWriter) cancel() {
+	atomic.StoreInt32(&w.canceled, 1)
+}
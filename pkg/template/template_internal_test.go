@@ -0,0 +1,22 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/Masterminds/sprig/v3"
+)
+
+func Test_safeFunctions(t *testing.T) {
+	t.Parallel()
+	all := sprig.TxtFuncMap()
+	for _, name := range safeFunctions {
+		if _, ok := all[name]; !ok {
+			t.Errorf("sprig function isn't found: %s", name)
+		}
+	}
+	for _, name := range []string{"env", "expandenv", "getHostByName", "repeat", "until"} {
+		if _, ok := safeFuncMap()[name]; ok {
+			t.Errorf("function must not be allowed: %s", name)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package template_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/suzuki-shunsuke/lambuild/pkg/template"
@@ -68,3 +69,130 @@ func TestNewString(t *testing.T) {
 		t.Fatal(`Template must be "foo"`)
 	}
 }
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	largeList := make([]string, 70*1024)
+	for i := range largeList {
+		largeList[i] = "a"
+	}
+	data := []struct {
+		title  string
+		tpl    string
+		param  interface{}
+		exp    string
+		isErr  bool
+		expErr error
+	}{
+		{
+			title: "safe function",
+			tpl:   `{{.name | upper}}`,
+			param: map[string]interface{}{
+				"name": "foo",
+			},
+			exp: "FOO",
+		},
+		{
+			title: "env can't be used",
+			tpl:   `{{env "HOME"}}`,
+			isErr: true,
+		},
+		{
+			title: "expandenv can't be used",
+			tpl:   `{{expandenv "$HOME"}}`,
+			isErr: true,
+		},
+		{
+			title: "output is too large",
+			tpl:   `{{range .list}}{{.}}{{end}}`,
+			param: map[string]interface{}{
+				"list": largeList,
+			},
+			isErr:  true,
+			expErr: template.ErrOutputTooLarge,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			tpl, err := template.New(d.tpl)
+			if err != nil {
+				if d.isErr && d.expErr == nil {
+					return
+				}
+				t.Fatal(err)
+			}
+			s, err := tpl.Execute(d.param)
+			if err != nil {
+				if !d.isErr {
+					t.Fatal(err)
+				}
+				if d.expErr != nil && !errors.Is(err, d.expErr) {
+					t.Fatalf("got an error %v, wanted %v", err, d.expErr)
+				}
+				return
+			}
+			if d.isErr {
+				t.Fatal("error should be returned")
+			}
+			if s != d.exp {
+				t.Fatalf(`got "%s", wanted "%s"`, s, d.exp)
+			}
+		})
+	}
+}
+
+func TestTrustedTemplate_Compile(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title        string
+		tpl          string
+		allFunctions bool
+		isErr        bool
+	}{
+		{
+			title: "safe function",
+			tpl:   `{{"foo" | upper}}`,
+		},
+		{
+			title: "env isn't allowed by default",
+			tpl:   `{{env "HOME"}}`,
+			isErr: true,
+		},
+		{
+			title:        "env is allowed",
+			tpl:          `{{env "HOME"}}`,
+			allFunctions: true,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			tpl, err := template.NewTrusted(d.tpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tpl.Empty() {
+				t.Fatal("template is empty")
+			}
+			if _, err := tpl.Execute(nil); err == nil {
+				t.Fatal("template which isn't compiled shouldn't be executed")
+			}
+			err = tpl.Compile(d.allFunctions)
+			if d.isErr {
+				if err == nil {
+					t.Fatal("error should be returned")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tpl.Execute(nil); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	bspec "github.com/suzuki-shunsuke/lambuild/pkg/buildspec"
+	"github.com/suzuki-shunsuke/lambuild/pkg/yamlutil"
+	yamlv2 "gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Issue is a problem of a configuration file.
+type Issue struct {
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (issue Issue) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", issue.Path, issue.Line, issue.Column, issue.Message)
+}
+
+// Error is returned when a configuration file has issues.
+type Error struct {
+	Issues []Issue
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		msgs[i] = issue.String()
+	}
+	return "the configuration file is invalid:\n" + strings.Join(msgs, "\n")
+}
+
+// inlineKeys are keys which are allowed in the inline map of the type.
+var inlineKeys = map[reflect.Type][]string{ //nolint:gochecknoglobals
+	reflect.TypeOf(bspec.Buildspec{}): bspec.CodeBuildKeys,
+	reflect.TypeOf(bspec.Phase{}):     bspec.PhaseCodeBuildKeys,
+}
+
+var unmarshalerType = reflect.TypeOf((*yamlv2.Unmarshaler)(nil)).Elem() //nolint:gochecknoglobals
+
+// Validate validates a lambuild configuration file.
+// Validate reports unknown keys, invalid expressions and templates,
+// and problems of build-graph and build-list such as duplicate identifiers, missing dependencies, and dependency cycles.
+// If the content isn't a valid YAML, an error is returned.
+func Validate(path string, content []byte) ([]Issue, error) {
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(content, root); err != nil {
+		return nil, fmt.Errorf("parse a configuration file as YAML (%s): %w", path, err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+	v := &validator{path: path}
+	doc := root.Content[0]
+	v.validateNode(doc, reflect.TypeOf(bspec.Buildspec{}), "")
+	if batch := getMapValue(doc, "batch"); batch != nil {
+		v.validateGraph(getMapValue(batch, "build-graph"))
+		v.getIdentifiers(getMapValue(batch, "build-list"), "build-list")
+	}
+	sort.SliceStable(v.issues, func(i, j int) bool {
+		if v.issues[i].Line != v.issues[j].Line {
+			return v.issues[i].Line < v.issues[j].Line
+		}
+		return v.issues[i].Column < v.issues[j].Column
+	})
+	return v.issues, nil
+}
+
+type validator struct {
+	path   string
+	issues []Issue
+}
+
+func (v *validator) add(node *yaml.Node, format string, args ...interface{}) {
+	v.issues = append(v.issues, Issue{
+		Path:    v.path,
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// validateNode validates the node according to the Go type which the node is decoded into.
+func (v *validator) validateNode(node *yaml.Node, typ reflect.Type, keyPath string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if reflect.PtrTo(typ).Implements(unmarshalerType) {
+		v.decode(node, typ, keyPath)
+		return
+	}
+	switch typ.Kind() { //nolint:exhaustive
+	case reflect.Ptr:
+		v.validateNode(node, typ.Elem(), keyPath)
+	case reflect.Struct:
+		v.validateStruct(node, typ, keyPath)
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			v.decode(node, typ, keyPath)
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v.validateNode(node.Content[i+1], typ.Elem(), joinKeyPath(keyPath, node.Content[i].Value))
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			v.decode(node, typ, keyPath)
+			return
+		}
+		for i, elem := range node.Content {
+			v.validateNode(elem, typ.Elem(), fmt.Sprintf("%s[%d]", keyPath, i))
+		}
+	case reflect.Interface:
+	default:
+		v.decode(node, typ, keyPath)
+	}
+}
+
+func (v *validator) validateStruct(node *yaml.Node, typ reflect.Type, keyPath string) {
+	if node.Kind != yaml.MappingNode {
+		if node.Tag == "!!null" {
+			return
+		}
+		v.add(node, "%s must be a map", describeKeyPath(keyPath))
+		return
+	}
+	fieldList, inline := yamlutil.Fields(typ)
+	fields := make(map[string]reflect.Type, len(fieldList))
+	for _, field := range fieldList {
+		fields[field.Key] = field.Type
+	}
+	allowed := map[string]struct{}{}
+	if inline {
+		for _, k := range inlineKeys[typ] {
+			allowed[k] = struct{}{}
+		}
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		key := keyNode.Value
+		fieldType, ok := fields[key]
+		if !ok {
+			if _, ok := allowed[key]; !ok {
+				v.add(keyNode, "unknown key %q in %s", key, describeKeyPath(keyPath))
+			}
+			continue
+		}
+		v.validateNode(node.Content[i+1], fieldType, joinKeyPath(keyPath, key))
+	}
+}
+
+// decode decodes the node into the Go type to check whether the node is valid.
+// Expressions and templates are compiled in UnmarshalYAML.
+func (v *validator) decode(node *yaml.Node, typ reflect.Type, keyPath string) {
+	b, err := yaml.Marshal(node)
+	if err != nil {
+		v.add(node, "%s is invalid: %v", describeKeyPath(keyPath), err)
+		return
+	}
+	if err := yamlv2.Unmarshal(b, reflect.New(typ).Interface()); err != nil {
+		v.add(node, "%s is invalid: %v", describeKeyPath(keyPath), err)
+	}
+}
+
+type identifier struct {
+	Name     string
+	Node     *yaml.Node
+	DependOn []*yaml.Node
+}
+
+// getIdentifiers returns identifiers of build-graph or build-list elements.
+// Duplicate identifiers are reported.
+func (v *validator) getIdentifiers(node *yaml.Node, keyPath string) []identifier {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	ids := make([]identifier, 0, len(node.Content))
+	names := make(map[string]*yaml.Node, len(node.Content))
+	for i, elem := range node.Content {
+		if elem.Kind != yaml.MappingNode {
+			continue
+		}
+		idNode := getMapValue(elem, "identifier")
+		if idNode == nil || idNode.Value == "" {
+			v.add(elem, "%s[%d].identifier is required", keyPath, i)
+			continue
+		}
+		if prev, ok := names[idNode.Value]; ok {
+			v.add(idNode, "duplicate identifier %q in %s (first defined at line %d)", idNode.Value, keyPath, prev.Line)
+			continue
+		}
+		names[idNode.Value] = idNode
+		id := identifier{
+			Name: idNode.Value,
+			Node: idNode,
+		}
+		if deps := getMapValue(elem, "depend-on"); deps != nil && deps.Kind == yaml.SequenceNode {
+			id.DependOn = deps.Content
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// validateGraph reports duplicate identifiers, missing dependencies, and dependency cycles of build-graph.
+func (v *validator) validateGraph(node *yaml.Node) {
+	ids := v.getIdentifiers(node, "build-graph")
+	graph := make(map[string]identifier, len(ids))
+	for _, id := range ids {
+		graph[id.Name] = id
+	}
+	for _, id := range ids {
+		for _, dep := range id.DependOn {
+			if _, ok := graph[dep.Value]; !ok {
+				v.add(dep, "%q depends on %q but it isn't defined in build-graph", id.Name, dep.Value)
+			}
+		}
+	}
+	for _, cycle := range findCycles(ids, graph) {
+		v.add(graph[cycle[0]].Node, "dependency cycle in build-graph: %s", strings.Join(cycle, " -> "))
+	}
+}
+
+const (
+	unvisited = iota
+	visiting
+	visited
+)
+
+// findCycles finds dependency cycles with depth first search.
+// Each cycle is reported once.
+func findCycles(ids []identifier, graph map[string]identifier) [][]string {
+	states := make(map[string]int, len(ids))
+	cycles := [][]string{}
+	var stack []string
+	var visit func(name string)
+	visit = func(name string) {
+		states[name] = visiting
+		stack = append(stack, name)
+		for _, dep := range graph[name].DependOn {
+			if _, ok := graph[dep.Value]; !ok {
+				continue
+			}
+			switch states[dep.Value] {
+			case unvisited:
+				visit(dep.Value)
+			case visiting:
+				for i, s := range stack {
+					if s == dep.Value {
+						cycle := append([]string{}, stack[i:]...)
+						cycles = append(cycles, append(cycle, dep.Value))
+						break
+					}
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		states[name] = visited
+	}
+	for _, id := range ids {
+		if states[id.Name] == unvisited {
+			visit(id.Name)
+		}
+	}
+	return cycles
+}
+
+func getMapValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func joinKeyPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+func describeKeyPath(keyPath string) string {
+	if keyPath == "" {
+		return "the top level"
+	}
+	return keyPath
+}
@@ -0,0 +1,147 @@
+package validator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/suzuki-shunsuke/lambuild/pkg/validator"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	data := []struct {
+		title   string
+		content string
+		exp     []string
+		isErr   bool
+	}{
+		{
+			title: "valid",
+			content: `version: 0.2
+env:
+  variables:
+    FOO: foo
+lambuild:
+  build-status-context: "foo ({{.event.Headers.Event}})"
+  if: event.Headers.Event == "push"
+  env:
+    variables:
+      BAR: event.Headers.Event
+batch:
+  build-graph:
+  - identifier: foo
+    if: event.Headers.Event == "push"
+  - identifier: bar
+    depend-on:
+    - foo
+phases:
+  build:
+    on-failure: ABORT
+    commands:
+    - echo foo
+    - command: echo bar
+      if: "true"
+`,
+			exp: []string{},
+		},
+		{
+			title: "unknown keys",
+			content: `version: 0.2
+lambuid:
+  if: "true"
+lambuild:
+  itmes: []
+batch:
+  build-grpah: []
+phases:
+  build:
+    comands: []
+`,
+			exp: []string{
+				`lambuild.yaml:2:1: unknown key "lambuid" in the top level`,
+				`lambuild.yaml:5:3: unknown key "itmes" in lambuild`,
+				`lambuild.yaml:7:3: unknown key "build-grpah" in batch`,
+				`lambuild.yaml:10:5: unknown key "comands" in phases.build`,
+			},
+		},
+		{
+			title: "invalid expression and template",
+			content: `lambuild:
+  build-status-context: "{{.foo"
+  items:
+  - if: "event.Headers.Event =="
+`,
+			exp: []string{
+				`lambuild.yaml:2:25: lambuild.build-status-context is invalid`,
+				`lambuild.yaml:4:9: lambuild.items[0].if is invalid`,
+			},
+		},
+		{
+			title: "graph",
+			content: `batch:
+  build-graph:
+  - identifier: foo
+    depend-on:
+    - bar
+  - identifier: bar
+    depend-on:
+    - foo
+  - identifier: zoo
+    depend-on:
+    - yoo
+  - identifier: foo
+`,
+			exp: []string{
+				`lambuild.yaml:3:17: dependency cycle in build-graph: foo -> bar -> foo`,
+				`lambuild.yaml:11:7: "zoo" depends on "yoo" but it isn't defined in build-graph`,
+				`lambuild.yaml:12:17: duplicate identifier "foo" in build-graph (first defined at line 3)`,
+			},
+		},
+		{
+			title: "list",
+			content: `batch:
+  build-list:
+  - identifier: foo
+  - identifier: foo
+  - buildspec: foo.yaml
+`,
+			exp: []string{
+				`lambuild.yaml:4:17: duplicate identifier "foo" in build-list (first defined at line 3)`,
+				`lambuild.yaml:5:5: build-list[2].identifier is required`,
+			},
+		},
+		{
+			title:   "invalid YAML",
+			content: `foo: [`,
+			isErr:   true,
+		},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(d.title, func(t *testing.T) {
+			t.Parallel()
+			issues, err := validator.Validate("lambuild.yaml", []byte(d.content))
+			if err != nil {
+				if d.isErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if d.isErr {
+				t.Fatal("error must be returned")
+			}
+			msgs := make([]string, len(issues))
+			for i, issue := range issues {
+				msgs[i] = issue.String()
+				// error messages of expressions and templates depend on the libraries
+				if len(d.exp) > i && strings.HasPrefix(msgs[i], d.exp[i]) {
+					msgs[i] = d.exp[i]
+				}
+			}
+			if diff := cmp.Diff(d.exp, msgs); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package yamlutil
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Field is a struct field which is decoded from YAML by gopkg.in/yaml.v2.
+type Field struct {
+	// Key is the YAML key of the field.
+	Key  string
+	Type reflect.Type
+}
+
+// Fields returns fields of the struct type in the declaration order.
+// The rule of keys is same as gopkg.in/yaml.v2.
+// The second returned value is true if the struct has an inline field.
+func Fields(typ reflect.Type) ([]Field, bool) {
+	fields := make([]Field, 0, typ.NumField())
+	inline := false
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		if strings.Contains(tag, ",inline") {
+			inline = true
+			continue
+		}
+		key := strings.Split(tag, ",")[0]
+		if key == "" {
+			key = strings.ToLower(field.Name)
+		}
+		fields = append(fields, Field{
+			Key:  key,
+			Type: field.Type,
+		})
+	}
+	return fields, inline
+}
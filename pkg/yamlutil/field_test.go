@@ -0,0 +1,31 @@
+package yamlutil_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/suzuki-shunsuke/lambuild/pkg/yamlutil"
+)
+
+type testStruct struct {
+	Name        string
+	ProjectName string                 `yaml:"project-name,omitempty"`
+	Ignored     string                 `yaml:"-"`
+	Map         map[string]interface{} `yaml:",inline"`
+}
+
+func TestFields(t *testing.T) {
+	t.Parallel()
+	fields, inline := yamlutil.Fields(reflect.TypeOf(testStruct{}))
+	if !inline {
+		t.Fatal("inline must be true")
+	}
+	keys := make([]string, len(fields))
+	for i, field := range fields {
+		keys[i] = field.Key
+	}
+	if diff := cmp.Diff([]string{"name", "project-name"}, keys); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -85,6 +85,14 @@ data "aws_iam_policy_document" "start-codebuild" {
     actions = [
       "codebuild:StartBuildBatch",
       "codebuild:StartBuild",
+      "codebuild:ListBuildsForProject",
+      "codebuild:ListBuildBatchesForProject",
+      "codebuild:BatchGetBuilds",
+      "codebuild:BatchGetBuildBatches",
+      "codebuild:RetryBuild",
+      "codebuild:RetryBuildBatch",
+      "codebuild:StopBuild",
+      "codebuild:StopBuildBatch",
     ]
 
     resources = [